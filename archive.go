@@ -0,0 +1,43 @@
+package configstore
+
+import (
+	"os"
+)
+
+// WithMaxSize 限制配置文件的大小：保存时加密后的数据超过 maxSize 字节，先将当前文件归档为 filename.old，再写入新文件。
+// 读取时新文件不存在或为空则读取 filename.old。与 WithBackup 不同，只保留一个归档文件，仅对 FileBackend 生效。
+func WithMaxSize(maxSize int64) Option {
+	return func(c *storeConfig) {
+		c.maxSize = maxSize
+	}
+}
+
+// 归档文件名
+func archiveName(filename string) string {
+	return filename + ".old"
+}
+
+// 将非空的 filename 重命名为归档文件，覆盖之前的归档
+func archiveFile(filename string) error {
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) || (err == nil && info.Size() == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return renameFile(filename, archiveName(filename))
+}
+
+// 配置文件为空时读取归档文件，未启用 WithMaxSize 时返回 nil
+func (cs *ConfigStore[T]) readArchive() ([]byte, error) {
+	fb, ok := cs.fileBackend()
+	if !ok || cs.maxSize <= 0 {
+		return nil, nil
+	}
+	data, err := readFile(archiveName(fb.filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
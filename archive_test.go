@@ -0,0 +1,60 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type auditConfig struct {
+	Name  string   `json:"name"`
+	Audit []string `json:"audit"`
+}
+
+func TestMaxSize(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.data")
+	cs, err := NewConfigStore[auditConfig](filename, "0123456789abcdef", WithMaxSize(256))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 未超过大小限制时不归档
+	if err := cs.SaveConfig(auditConfig{Name: "small"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := os.Stat(archiveName(filename)); !os.IsNotExist(err) {
+		t.Errorf("Expected no archive, but got: %v", err)
+	}
+
+	large := auditConfig{Name: "large", Audit: []string{strings.Repeat("x", 512)}}
+	if err := cs.SaveConfig(large); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 之前的文件被归档，新文件保存当前配置
+	archived, err := NewConfigStore[auditConfig](archiveName(filename), "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config, err := archived.LoadConfig(); err != nil || config.Name != "small" {
+		t.Errorf("Expected archived config small, but got: %+v, %v", config, err)
+	}
+	if config, err := cs.LoadConfig(); err != nil || config.Name != "large" {
+		t.Errorf("Expected current config large, but got: %+v, %v", config, err)
+	}
+
+	// 新文件为空时读取归档
+	if err := os.Truncate(filename, 0); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config, err := cs.LoadConfig(); err != nil || config.Name != "small" {
+		t.Errorf("Expected fallback to archived config, but got: %+v, %v", config, err)
+	}
+
+	if err := cs.DeleteConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := os.Stat(archiveName(filename)); !os.IsNotExist(err) {
+		t.Errorf("Expected archive to be removed, but got: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package configstore
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+// Backend 是加密配置数据的存储后端，例如本地文件、内存、S3、etcd 等。
+// 还没有写入过数据时 Read 应返回空数据和 nil 错误。
+type Backend interface {
+	Read() ([]byte, error)
+	Write([]byte) error
+}
+
+// Deleter 是可选接口，实现了该接口的 Backend 支持 DeleteConfig。
+// 数据不存在时 Delete 应返回 nil。
+type Deleter interface {
+	Delete() error
+}
+
+// BackendWatcher 是可选接口，实现了该接口的 Backend 支持 ConfigStore.Watch。
+// Watch 在数据发生变化时调用 onChange，直到 ctx 被取消后返回 nil，无法继续监听时返回错误。
+type BackendWatcher interface {
+	Watch(ctx context.Context, onChange func()) error
+}
+
+// FileBackend 将配置数据保存在本地文件中，是默认的存储后端
+type FileBackend struct {
+	filename string
+	mode     os.FileMode
+	sync     bool
+}
+
+// NewFileBackend 创建一个保存到 filename 的 FileBackend，写入的文件权限为 DefaultFileMode，写入后刷新到磁盘
+func NewFileBackend(filename string) *FileBackend {
+	return &FileBackend{filename: filename, mode: DefaultFileMode, sync: true}
+}
+
+// Filename 返回配置文件的路径
+func (b *FileBackend) Filename() string {
+	return b.filename
+}
+
+func (b *FileBackend) Read() ([]byte, error) {
+	data, err := readFile(b.filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (b *FileBackend) Write(data []byte) error {
+	return writeFile(b.filename, data, b.mode, b.sync)
+}
+
+// Delete 删除配置文件以及残留的临时文件，文件不存在时返回 nil
+func (b *FileBackend) Delete() error {
+	for _, name := range []string{b.filename, b.filename + ".tmp"} {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// 返回文件存储后端，使用其他后端时 ok 为 false
+func (cs *ConfigStore[T]) fileBackend() (*FileBackend, bool) {
+	fb, ok := cs.backend.(*FileBackend)
+	return fb, ok
+}
+
+// MemoryBackend 将配置数据保存在内存中，适用于测试和临时配置
+type MemoryBackend struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+// NewMemoryBackend 创建一个空的 MemoryBackend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+func (b *MemoryBackend) Read() ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.data...), nil
+}
+
+func (b *MemoryBackend) Write(data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append([]byte(nil), data...)
+	return nil
+}
+
+func (b *MemoryBackend) Delete() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = nil
+	return nil
+}
+
+// 清零并释放保存的数据
+func (b *MemoryBackend) wipe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.data)
+	b.data = nil
+}
@@ -0,0 +1,78 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "memory.data")
+	backend := NewMemoryBackend()
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithBackend(backend))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 使用 MemoryBackend 时不会创建文件
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("Expected file to not exist, but got: %v", err)
+	}
+
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data, _ := backend.Read()
+	if len(data) == 0 {
+		t.Errorf("Expected encrypted data in the backend")
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+type failingBackend struct {
+	err error
+}
+
+func (b failingBackend) Read() ([]byte, error) { return nil, b.err }
+func (b failingBackend) Write([]byte) error    { return b.err }
+
+func TestBackendErrors(t *testing.T) {
+	backendErr := errors.New("backend unavailable")
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(failingBackend{err: backendErr}))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{}); !errors.Is(err, backendErr) {
+		t.Errorf("Expected backend error, but got: %v", err)
+	}
+	defaultConfig := myConfig{Username: "default"}
+	loadConfig, err := cs.LoadConfigOrDefault(defaultConfig)
+	if !errors.Is(err, backendErr) {
+		t.Errorf("Expected backend error, but got: %v", err)
+	}
+	if loadConfig != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v", loadConfig)
+	}
+}
+
+func TestDefaultFileBackend(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "file.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	fb, ok := cs.fileBackend()
+	if !ok {
+		t.Fatalf("Expected a FileBackend by default")
+	}
+	if fb.Filename() != filename {
+		t.Errorf("Expected filename to be %s, but got: %s", filename, fb.Filename())
+	}
+}
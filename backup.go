@@ -0,0 +1,183 @@
+package configstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 第 n 个备份文件的文件名，n 从 1 开始，1 为最新的备份
+func backupName(filename string, n int) string {
+	return fmt.Sprintf("%s.bak.%d", filename, n)
+}
+
+// 保存前轮转备份：.bak.1 -> .bak.2 -> ... -> .bak.max，当前文件成为新的 .bak.1。
+// 当前文件以硬链接（不支持时复制）的方式备份，随后的原子写入不会出现主文件缺失的窗口。
+func rotateBackups(filename string, maxBackups int) error {
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) || (err == nil && info.Size() == 0) {
+		// 还没有保存过配置，不需要备份
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(backupName(filename, maxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(backupName(filename, i), backupName(filename, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return linkOrCopy(filename, backupName(filename, 1))
+}
+
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// RestoreBackup 使用第 n 个备份（1 为最新）恢复配置文件，恢复前会校验备份能够正常解密
+func (cs *ConfigStore[T]) RestoreBackup(n int) (err error) {
+	defer func() { err = cs.wrapError("restore", err) }()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.closed {
+		return ErrStoreClosed
+	}
+	fb, ok := cs.fileBackend()
+	if !ok {
+		return fmt.Errorf("%w: backups are only supported by FileBackend", ErrUnsupported)
+	}
+	if n < 1 || (cs.maxBackups > 0 && n > cs.maxBackups) {
+		return fmt.Errorf("%w: invalid backup number %d", ErrInvalidOption, n)
+	}
+	name := backupName(fb.filename, n)
+	data, err := readFile(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("backup %d: %w", n, ErrFileNotFound)
+		}
+		return err
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("%w: backup %d is empty", ErrCorruptData, n)
+	}
+
+	secret, err := cs.secret(context.Background())
+	if err != nil {
+		return err
+	}
+	if _, _, err := cs.open(secret, data); err != nil {
+		return fmt.Errorf("backup %d is not readable: %w", n, err)
+	}
+	unlock, err := cs.lockFile(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cs.invalidateCache()
+	if err := fb.Write(data); err != nil {
+		return err
+	}
+	cs.recordModTime()
+	return cs.writeChecksum(data)
+}
+
+// 使用新 key 重新加密所有已存在的备份，返回备份文件名到新内容的映射
+func (cs *ConfigStore[T]) reencryptBackups(oldSecret, newSecret []byte) (map[string][]byte, error) {
+	backups := make(map[string][]byte)
+	fb, ok := cs.fileBackend()
+	if !ok {
+		return backups, nil
+	}
+	for i := 1; i <= cs.maxBackups; i++ {
+		name := backupName(fb.filename, i)
+		data, err := readFile(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		plaintext, header, err := cs.open(oldSecret, data)
+		if err != nil {
+			return nil, fmt.Errorf("backup %d is not readable: %w", i, err)
+		}
+		if backups[name], err = cs.seal(newSecret, plaintext, int(header.dataVersion), header.savedTime()); err != nil {
+			return nil, err
+		}
+	}
+	return backups, nil
+}
+
+// 删除 filename 的所有备份文件（filename.bak.N），不受当前 maxBackups 的限制
+func removeBackups(filename string) error {
+	names, err := backupFiles(filename)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// 列出 filename 的所有备份文件
+func backupFiles(filename string) ([]string, error) {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	prefix := base + ".bak."
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+		if _, err := strconv.Atoi(suffix); err != nil {
+			continue
+		}
+		names = append(names, filepath.Join(dir, entry.Name()))
+	}
+	return names, nil
+}
@@ -0,0 +1,136 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupRotation(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "backup.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key, WithBackup(2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2", "v3", "v4"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	// 最多保留 2 个备份
+	if _, err := os.Stat(backupName(filename, 3)); !os.IsNotExist(err) {
+		t.Errorf("Expected backup 3 to not exist, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig.Username != "v4" {
+		t.Errorf("Expected username to be v4, but got: %s", loadConfig.Username)
+	}
+
+	// 恢复到第 2 个备份
+	if err := cs.RestoreBackup(2); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err = cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig.Username != "v2" {
+		t.Errorf("Expected username to be v2, but got: %s", loadConfig.Username)
+	}
+}
+
+func TestBackupSkipsEmptyFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "backup.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithBackup(3))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "v1"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := os.Stat(backupName(filename, 1)); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup for the empty initial file, but got: %v", err)
+	}
+}
+
+func TestRestoreMissingBackup(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "backup.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithBackup(3))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.RestoreBackup(1); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound for a missing backup, but got: %v", err)
+	}
+	if err := cs.RestoreBackup(4); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected an error for an out of range backup, but got nil")
+	}
+}
+
+func TestRotateKeyReencryptsBackups(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "backup.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithBackup(2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	if err := cs.RotateKey("fedcba9876543210"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.RestoreBackup(1); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig.Username != "v1" {
+		t.Errorf("Expected username to be v1, but got: %s", loadConfig.Username)
+	}
+}
+
+func TestRestoreBackupThenSave(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "restore.data")
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithBackup(2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	if _, err := cs.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 将修改时间设置到过去，保证恢复后主文件的修改时间不同
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filename, past, past); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := cs.RestoreBackup(1); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 恢复不会被当作外部修改
+	if err := cs.SaveConfig(myConfig{Username: "v3"}); err != nil {
+		t.Fatalf("Expected no error after restore, but got: %v", err)
+	}
+	if config, err := cs.LoadConfig(); err != nil || config.Username != "v3" {
+		t.Errorf("Expected username v3, but got: %+v, %v", config, err)
+	}
+}
@@ -0,0 +1,62 @@
+package configstore
+
+import (
+	"context"
+	"time"
+)
+
+// WithCache 在内存中缓存最近一次成功读取的配置，之后的读取直接返回缓存而不再访问存储后端。
+// SaveConfig、DeleteConfig 和 RestoreBackup 会使缓存失效，可以通过 Refresh 强制重新读取。
+// 缓存的是 T 的浅拷贝，调用方不应修改返回值中的 map、slice 等引用类型。
+func WithCache() Option {
+	return func(c *storeConfig) {
+		c.cache = true
+	}
+}
+
+// Refresh 从存储后端重新读取配置并更新缓存，读取失败时清空缓存并返回错误
+func (cs *ConfigStore[T]) Refresh() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	_, err := cs.loadAndCache(context.Background())
+	return cs.wrapError("refresh", err)
+}
+
+// IsCached 返回当前是否存在未过期的缓存配置
+func (cs *ConfigStore[T]) IsCached() bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.cacheValid()
+}
+
+// 返回缓存的配置，没有缓存或缓存的配置已经过期时 ok 为 false
+func (cs *ConfigStore[T]) cachedConfig() (config T, ok bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.cached, cs.cacheValid()
+}
+
+// 是否存在未过期的缓存，调用方需要持有锁
+func (cs *ConfigStore[T]) cacheValid() bool {
+	return cs.hasCached && cs.checkExpired(cs.cachedSavedAt) == nil
+}
+
+// 读取配置并更新缓存，调用方需要持有写锁
+func (cs *ConfigStore[T]) loadAndCache(ctx context.Context) (T, error) {
+	config, savedAt, err := cs.loadConfig(ctx)
+	if err != nil || !cs.cache {
+		cs.invalidateCache()
+		return config, err
+	}
+	cs.cached, cs.cachedSavedAt, cs.hasCached = config, savedAt, true
+	if cs.breaker != nil {
+		cs.stale, cs.hasStale = config, true
+	}
+	return config, nil
+}
+
+// 清空缓存，调用方需要持有写锁
+func (cs *ConfigStore[T]) invalidateCache() {
+	var zero T
+	cs.cached, cs.cachedSavedAt, cs.hasCached = zero, time.Time{}, false
+}
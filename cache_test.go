@@ -0,0 +1,109 @@
+package configstore
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// 记录读取次数的存储后端
+type countingBackend struct {
+	MemoryBackend
+	reads atomic.Int32
+}
+
+func (b *countingBackend) Read() ([]byte, error) {
+	b.reads.Add(1)
+	return b.MemoryBackend.Read()
+}
+
+func TestCache(t *testing.T) {
+	backend := &countingBackend{}
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(backend), WithCache())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if cs.IsCached() {
+		t.Error("Expected no cached config before the first load")
+	}
+
+	for i := 0; i < 3; i++ {
+		config, err := cs.LoadConfigOrDefault(myConfig{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if config.Username != "testuser" {
+			t.Errorf("Expected username testuser, but got: %s", config.Username)
+		}
+	}
+	if n := backend.reads.Load(); n != 1 {
+		t.Errorf("Expected 1 backend read, but got: %d", n)
+	}
+	if !cs.IsCached() {
+		t.Error("Expected config to be cached")
+	}
+
+	// 保存后缓存失效
+	if err := cs.SaveConfig(myConfig{Username: "newuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if cs.IsCached() {
+		t.Error("Expected cache to be invalidated by SaveConfig")
+	}
+	config, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Username != "newuser" {
+		t.Errorf("Expected username newuser, but got: %s", config.Username)
+	}
+	if n := backend.reads.Load(); n != 2 {
+		t.Errorf("Expected 2 backend reads, but got: %d", n)
+	}
+}
+
+func TestCacheRefresh(t *testing.T) {
+	backend := &countingBackend{}
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(backend), WithCache())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 另一个 ConfigStore 直接修改存储的数据，缓存不会感知
+	other, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(&backend.MemoryBackend))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := other.SaveConfig(myConfig{Username: "v1"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.Refresh(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := other.SaveConfig(myConfig{Username: "v2"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config, _ := cs.LoadConfigOrDefault(myConfig{}); config.Username != "v1" {
+		t.Errorf("Expected cached username v1, but got: %s", config.Username)
+	}
+
+	if err := cs.Refresh(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config, _ := cs.LoadConfigOrDefault(myConfig{}); config.Username != "v2" {
+		t.Errorf("Expected refreshed username v2, but got: %s", config.Username)
+	}
+
+	// 没有配置时 Refresh 返回 ErrNoConfig 并清空缓存
+	if err := cs.DeleteConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.Refresh(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+	if cs.IsCached() {
+		t.Error("Expected no cached config after a failed refresh")
+	}
+}
@@ -0,0 +1,55 @@
+package configstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// WithChecksumFile 在每次保存后写入 filename.sha256 文件，内容为加密后文件内容的十六进制 SHA-256，
+// 便于 Ansible、Chef 等部署系统校验文件。读取配置时先校验该文件，文件不存在时跳过校验，
+// 与不一致时返回 ErrChecksumMismatch。仅对 FileBackend 生效。
+func WithChecksumFile() Option {
+	return func(c *storeConfig) {
+		c.checksumFile = true
+	}
+}
+
+// 校验文件的文件名
+func checksumName(filename string) string {
+	return filename + ".sha256"
+}
+
+// 写入 data 的校验文件，调用方需要持有文件锁
+func (cs *ConfigStore[T]) writeChecksum(data []byte) error {
+	fb, ok := cs.fileBackend()
+	if !ok || !cs.checksumFile {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	return writeFile(checksumName(fb.filename), []byte(hex.EncodeToString(sum[:])+"\n"), cs.fileMode, cs.sync)
+}
+
+// 使用校验文件校验 data，兼容 sha256sum 输出的“校验和 文件名”格式，调用方需要持有文件锁
+func (cs *ConfigStore[T]) verifyChecksum(data []byte) error {
+	fb, ok := cs.fileBackend()
+	if !ok || !cs.checksumFile {
+		return nil
+	}
+	name := checksumName(fb.filename)
+	content, err := readFile(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fields := bytes.Fields(content)
+	sum := sha256.Sum256(data)
+	if len(fields) == 0 || !bytes.EqualFold(fields[0], []byte(hex.EncodeToString(sum[:]))) {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, name)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package configstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 校验文件的内容应为配置文件的 SHA-256
+func checkChecksumFile(t *testing.T, filename string) {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	sidecar, err := os.ReadFile(filename + ".sha256")
+	if err != nil {
+		t.Fatalf("Expected checksum file to exist, but got: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	if string(sidecar) != hex.EncodeToString(sum[:])+"\n" {
+		t.Errorf("Expected checksum %x, but got: %q", sum, sidecar)
+	}
+}
+
+func TestWithChecksumFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "checksum.data")
+	sidecar := filename + ".sha256"
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithChecksumFile())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	checkChecksumFile(t, filename)
+	if loaded, err := cs.LoadConfigOrDefault(myConfig{}); err != nil || loaded != config {
+		t.Errorf("Expected %+v, but got: %+v, %v", config, loaded, err)
+	}
+
+	// 兼容 sha256sum 的输出格式
+	data, _ := os.ReadFile(sidecar)
+	if err := os.WriteFile(sidecar, []byte(strings.TrimSpace(string(data))+"  checksum.data\n"), 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfigOrDefault(myConfig{}); err != nil {
+		t.Errorf("Expected sha256sum format to be accepted, but got: %v", err)
+	}
+
+	// 校验和不一致
+	if err := os.WriteFile(sidecar, []byte(strings.Repeat("0", 64)+"\n"), 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfigOrDefault(myConfig{}); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, but got: %v", err)
+	}
+
+	// 没有校验文件时跳过校验
+	if err := os.Remove(sidecar); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded, err := cs.LoadConfigOrDefault(myConfig{}); err != nil || loaded != config {
+		t.Errorf("Expected %+v, but got: %+v, %v", config, loaded, err)
+	}
+
+	// 轮换 key 后校验文件同步更新，删除配置时一并删除
+	if err := cs.RotateKey("fedcba9876543210"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	checkChecksumFile(t, filename)
+	if err := cs.DeleteConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := os.Stat(sidecar); !os.IsNotExist(err) {
+		t.Errorf("Expected checksum file to be removed, but got: %v", err)
+	}
+}
+
+func TestChecksumFileDisabled(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "checksum.data")
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := os.Stat(filename + ".sha256"); !os.IsNotExist(err) {
+		t.Errorf("Expected no checksum file, but got: %v", err)
+	}
+}
+
+func TestChecksumFileRestoreBackup(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "checksum.data")
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithChecksumFile(), WithBackup(2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	if err := cs.RestoreBackup(1); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 恢复后校验文件同样被更新
+	checkChecksumFile(t, filename)
+	if config, err := cs.LoadConfig(); err != nil || config.Username != "v1" {
+		t.Errorf("Expected username v1, but got: %+v, %v", config, err)
+	}
+}
@@ -0,0 +1,259 @@
+package configstore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// CipherMode 表示配置文件使用的加密模式
+type CipherMode uint8
+
+const (
+	// CipherModeCBC 使用 AES-CBC + PKCS7 填充，只保证机密性，是默认模式
+	CipherModeCBC CipherMode = iota
+	// CipherModeGCM 使用 AES-GCM，带认证标签，篡改或损坏的数据会在解密时直接报错
+	CipherModeGCM
+	// CipherModeChaCha20Poly1305 使用 ChaCha20-Poly1305，在没有 AES 硬件加速的设备上更快，要求 32 字节的 key
+	CipherModeChaCha20Poly1305
+	// CipherModeXChaCha20Poly1305 使用 24 字节 nonce 的 XChaCha20-Poly1305，随机 nonce 碰撞的概率更低
+	CipherModeXChaCha20Poly1305
+	// CipherModeNone 不加密整个文件，通常与 configstore:"encrypt" 字段级加密一起使用，
+	// 得到只有敏感字段被加密、其余部分可以直接查看的文件。key 的长度要求与 AES 相同。
+	CipherModeNone
+)
+
+// GCM 标准推荐的 nonce 长度
+const gcmNonceSize = 12
+
+// 密文长度不足以包含 IV/nonce
+var errInvalidCiphertext = fmt.Errorf("%w: invalid encrypted data", ErrCorruptData)
+
+// CBC 解密后的填充不合法，通常是 key 错误或密文被篡改
+var errInvalidPadding = fmt.Errorf("%w: invalid padding", ErrDecryptionFailed)
+
+func (m CipherMode) String() string {
+	switch m {
+	case CipherModeCBC:
+		return "AES-CBC"
+	case CipherModeGCM:
+		return "AES-GCM"
+	case CipherModeChaCha20Poly1305:
+		return "ChaCha20-Poly1305"
+	case CipherModeXChaCha20Poly1305:
+		return "XChaCha20-Poly1305"
+	case CipherModeNone:
+		return "none"
+	default:
+		return "unknown"
+	}
+}
+
+// 校验 key 的长度是否适用于该加密模式
+func (m CipherMode) checkKeyLen(n int) error {
+	switch m {
+	case CipherModeCBC, CipherModeGCM, CipherModeNone:
+		if n != 16 && n != 24 && n != 32 {
+			return fmt.Errorf("%w: must be 16 or 24 or 32, got %d", ErrInvalidKeyLength, n)
+		}
+	case CipherModeChaCha20Poly1305, CipherModeXChaCha20Poly1305:
+		if n != chacha20poly1305.KeySize {
+			return fmt.Errorf("%w: must be 32 for ChaCha20-Poly1305, got %d", ErrInvalidKeyLength, n)
+		}
+	default:
+		return fmt.Errorf("%w cipher mode %d", ErrUnsupported, m)
+	}
+	return nil
+}
+
+// Encode 使用 AES-CBC + PKCS7 填充加密单个值（例如 API key），返回随机 IV 与密文拼接后的结果，
+// 与 CipherModeCBC 下配置文件中的加密方式相同。key 的长度必须为 16、24 或 32 字节。
+// Encode 和 Decode 是底层的构建块：输出不包含文件头部，也没有认证标签，无法发现密文被篡改，
+// 需要认证时使用 ConfigStore 与 CipherModeGCM 或 WithIntegrity。
+func Encode(plaintext []byte, key string) ([]byte, error) {
+	if err := CipherModeCBC.checkKeyLen(len(key)); err != nil {
+		return nil, err
+	}
+	return sealData(CipherModeCBC, plaintext, []byte(key), rand.Reader)
+}
+
+// Decode 解密 Encode 的输出，key 错误或数据损坏时通常返回 ErrDecryptionFailed
+func Decode(ciphertext []byte, key string) ([]byte, error) {
+	if err := CipherModeCBC.checkKeyLen(len(key)); err != nil {
+		return nil, err
+	}
+	return openData(CipherModeCBC, ciphertext, []byte(key))
+}
+
+// 按加密模式加密数据，返回 IV/nonce 与密文拼接后的结果
+func sealData(mode CipherMode, plaintext []byte, key []byte, random io.Reader) ([]byte, error) {
+	switch mode {
+	case CipherModeCBC:
+		iv := make([]byte, aes.BlockSize)
+		if _, err := io.ReadFull(random, iv); err != nil {
+			return nil, err
+		}
+		ciphertext, err := encryptAES(plaintext, key, iv)
+		if err != nil {
+			return nil, err
+		}
+		return append(iv, ciphertext...), nil
+	case CipherModeGCM:
+		nonce := make([]byte, gcmNonceSize)
+		if _, err := io.ReadFull(random, nonce); err != nil {
+			return nil, err
+		}
+		return encryptGCM(plaintext, key, nonce)
+	case CipherModeChaCha20Poly1305, CipherModeXChaCha20Poly1305:
+		aead, err := newChaCha20Poly1305(mode, key)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := io.ReadFull(random, nonce); err != nil {
+			return nil, err
+		}
+		return aead.Seal(nonce, nonce, plaintext, nil), nil
+	case CipherModeNone:
+		return append([]byte(nil), plaintext...), nil
+	default:
+		return nil, fmt.Errorf("%w cipher mode %d", ErrUnsupported, mode)
+	}
+}
+
+// 按加密模式解密 sealData 的输出
+func openData(mode CipherMode, data []byte, key []byte) ([]byte, error) {
+	switch mode {
+	case CipherModeCBC:
+		// 提取 IV 和加密数据
+		if len(data) < aes.BlockSize {
+			return nil, errInvalidCiphertext
+		}
+		return decryptAES(data[aes.BlockSize:], key, data[:aes.BlockSize])
+	case CipherModeGCM:
+		if len(data) < gcmNonceSize {
+			return nil, errInvalidCiphertext
+		}
+		return decryptGCM(data[gcmNonceSize:], key, data[:gcmNonceSize])
+	case CipherModeChaCha20Poly1305, CipherModeXChaCha20Poly1305:
+		aead, err := newChaCha20Poly1305(mode, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < aead.NonceSize() {
+			return nil, errInvalidCiphertext
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
+		}
+		return plaintext, nil
+	case CipherModeNone:
+		return data, nil
+	default:
+		return nil, fmt.Errorf("%w cipher mode %d", ErrUnsupported, mode)
+	}
+}
+
+// 填充数据以满足 AES 块大小
+func pkcs7Padding(data []byte, blockSize int) []byte {
+	padding := blockSize - len(data)%blockSize
+	padtext := bytes.Repeat([]byte{byte(padding)}, padding)
+	return append(data, padtext...)
+}
+
+// 去除填充数据，填充不合法时返回错误而不是 panic
+func pkcs7UnPadding(data []byte, blockSize int) ([]byte, error) {
+	length := len(data)
+	if length == 0 || length%blockSize != 0 {
+		return nil, errInvalidPadding
+	}
+	unpadding := int(data[length-1])
+	if unpadding < 1 || unpadding > blockSize {
+		return nil, errInvalidPadding
+	}
+	// 检查所有填充字节是否相同，不提前返回
+	var bad byte
+	for _, b := range data[length-unpadding:] {
+		bad |= b ^ byte(unpadding)
+	}
+	if bad != 0 {
+		return nil, errInvalidPadding
+	}
+	return data[:(length - unpadding)], nil
+}
+
+// 加密数据
+func encryptAES(data []byte, key []byte, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	blockSize := block.BlockSize()
+	data = pkcs7Padding(data, blockSize)
+	ciphertext := make([]byte, len(data))
+	mode := cipher.NewCBCEncrypter(block, iv)
+	mode.CryptBlocks(ciphertext, data)
+	return ciphertext, nil
+}
+
+// 解密数据
+func decryptAES(data []byte, key []byte, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	blockSize := block.BlockSize()
+	// NewCBCDecrypter 在 IV 长度不等于块大小、CryptBlocks 在长度不是块大小的整数倍时会 panic
+	if len(iv) != blockSize || len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, errInvalidCiphertext
+	}
+	mode := cipher.NewCBCDecrypter(block, iv)
+	plaintext := make([]byte, len(data))
+	mode.CryptBlocks(plaintext, data)
+	return pkcs7UnPadding(plaintext, blockSize)
+}
+
+// 使用 AES-GCM 加密数据，nonce 放在密文前面
+func encryptGCM(data []byte, key []byte, nonce []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// 使用 AES-GCM 解密数据，认证失败时返回错误
+func decryptGCM(data []byte, key []byte, nonce []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
+	}
+	return plaintext, nil
+}
+
+// 创建 ChaCha20-Poly1305 或 XChaCha20-Poly1305 的 AEAD
+func newChaCha20Poly1305(mode CipherMode, key []byte) (cipher.AEAD, error) {
+	if mode == CipherModeXChaCha20Poly1305 {
+		return chacha20poly1305.NewX(key)
+	}
+	return chacha20poly1305.New(key)
+}
@@ -0,0 +1,241 @@
+package configstore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGCMSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "gcm.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key, WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// nonce 为 12 字节，再加上 16 字节的认证标签
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(data) < gcmNonceSize+16 {
+		t.Fatalf("Expected at least %d bytes, but got: %d", gcmNonceSize+16, len(data))
+	}
+
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestGCMTamperedFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "gcm.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key, WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 篡改最后一个字节，认证应当失败
+	data, _ := os.ReadFile(filename)
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	defaultConfig := myConfig{Username: "default"}
+	loadConfig, err := cs.LoadConfigOrDefault(defaultConfig)
+	if !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed for tampered data, but got: %v", err)
+	}
+	if loadConfig != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v", loadConfig)
+	}
+}
+
+func TestCBCDefaultMode(t *testing.T) {
+	// 不传入选项时仍使用 CBC，保证旧文件可以正常读取
+	filename := filepath.Join(t.TempDir(), "cbc.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if cs.cipherMode != CipherModeCBC {
+		t.Errorf("Expected cipher mode to be %v, but got: %v", CipherModeCBC, cs.cipherMode)
+	}
+}
+
+func TestChaCha20Poly1305SaveAndLoad(t *testing.T) {
+	key := "0123456789abcdef0123456789abcdef"
+	for _, mode := range []CipherMode{CipherModeChaCha20Poly1305, CipherModeXChaCha20Poly1305} {
+		t.Run(mode.String(), func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "chacha.data")
+			cs, err := NewConfigStore[myConfig](filename, key, WithCipherMode(mode))
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			config := myConfig{Username: "testuser", Password: "testpass"}
+			if err := cs.SaveConfig(config); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if loadConfig != config {
+				t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+			}
+		})
+	}
+}
+
+func TestChaCha20Poly1305KeyLength(t *testing.T) {
+	// ChaCha20-Poly1305 只接受 32 字节的 key
+	filename := filepath.Join(t.TempDir(), "chacha.data")
+	_, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithCipherMode(CipherModeChaCha20Poly1305))
+	if !errors.Is(err, ErrInvalidKeyLength) {
+		t.Errorf("Expected an error for a 16-byte key, but got nil")
+	}
+}
+
+func TestPKCS7UnPaddingInvalid(t *testing.T) {
+	valid := append(bytes.Repeat([]byte{'a'}, 12), 4, 4, 4, 4)
+	if data, err := pkcs7UnPadding(valid, 16); err != nil || len(data) != 12 {
+		t.Errorf("Expected 12 bytes and no error, but got: %d bytes, %v", len(data), err)
+	}
+
+	cases := map[string][]byte{
+		"empty":          {},
+		"not aligned":    bytes.Repeat([]byte{1}, 15),
+		"zero padding":   append(bytes.Repeat([]byte{'a'}, 15), 0),
+		"too large":      append(bytes.Repeat([]byte{'a'}, 15), 17),
+		"inconsistent":   append(bytes.Repeat([]byte{'a'}, 12), 1, 4, 4, 4),
+		"whole of block": bytes.Repeat([]byte{32}, 16),
+	}
+	for name, data := range cases {
+		if _, err := pkcs7UnPadding(data, 16); !errors.Is(err, ErrDecryptionFailed) {
+			t.Errorf("%s: expected ErrDecryptionFailed, but got: %v", name, err)
+		}
+	}
+}
+
+// 任意输入都不应使解密 panic
+func FuzzOpenData(f *testing.F) {
+	key := []byte("0123456789abcdef")
+	for _, mode := range []CipherMode{CipherModeCBC, CipherModeGCM, CipherModeChaCha20Poly1305} {
+		k := key
+		if mode == CipherModeChaCha20Poly1305 {
+			k = bytes.Repeat(key, 2)
+		}
+		sealed, err := sealData(mode, []byte(`{"username":"testuser"}`), k, rand.Reader)
+		if err != nil {
+			f.Fatalf("Expected no error, but got: %v", err)
+		}
+		f.Add(byte(mode), sealed)
+	}
+	f.Add(byte(CipherModeCBC), make([]byte, 17))
+	f.Add(byte(CipherModeCBC), make([]byte, 32))
+
+	f.Fuzz(func(t *testing.T, mode byte, data []byte) {
+		m := CipherMode(mode % 3)
+		k := key
+		if m == CipherModeChaCha20Poly1305 {
+			k = bytes.Repeat(key, 2)
+		}
+		openData(m, data, k)
+	})
+}
+
+func TestWithRandReader(t *testing.T) {
+	for _, mode := range []CipherMode{CipherModeCBC, CipherModeGCM} {
+		var outputs [2][]byte
+		for i := range outputs {
+			random := bytes.NewReader(bytes.Repeat([]byte{1}, 64))
+			cs, err := NewConfigStore[myConfig]("", "0123456789abcdef",
+				WithBackend(NewMemoryBackend()), WithCipherMode(mode), WithRandReader(random))
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := cs.EncryptConfig(myConfig{Username: "testuser"}, &buf); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			outputs[i] = buf.Bytes()
+		}
+		// 相同的随机数来源得到相同的输出
+		if !bytes.Equal(outputs[0], outputs[1]) {
+			t.Errorf("%s: expected deterministic output, but got different data", mode)
+		}
+	}
+}
+
+func TestWithRandReaderExhausted(t *testing.T) {
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef",
+		WithBackend(NewMemoryBackend()), WithRandReader(bytes.NewReader(nil)))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 随机数不足时保存失败，而不是使用全零的 IV
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err == nil {
+		t.Error("Expected an error when the random reader is exhausted")
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	key := "0123456789abcdef"
+	plaintext := []byte("sk-live-1234567890")
+	ciphertext, err := Encode(plaintext, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if bytes.Contains(ciphertext, plaintext) {
+		t.Error("Expected value to be encrypted")
+	}
+	// 每次使用新的 IV
+	other, _ := Encode(plaintext, key)
+	if bytes.Equal(ciphertext, other) {
+		t.Error("Expected different ciphertexts for the same value")
+	}
+	decoded, err := Decode(ciphertext, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !bytes.Equal(decoded, plaintext) {
+		t.Errorf("Expected %q, but got: %q", plaintext, decoded)
+	}
+
+	// 与配置文件使用相同的方案
+	sealed, err := sealData(CipherModeCBC, plaintext, []byte(key), rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if decoded, err := Decode(sealed, key); err != nil || !bytes.Equal(decoded, plaintext) {
+		t.Errorf("Expected Decode to read sealData output, but got: %q, %v", decoded, err)
+	}
+
+	if _, err := Encode(plaintext, "short"); !errors.Is(err, ErrInvalidKeyLength) {
+		t.Errorf("Expected ErrInvalidKeyLength, but got: %v", err)
+	}
+	// CBC 没有认证，错误的 key 偶尔也能得到合法的填充，只检查不会得到原文
+	if decoded, err := Decode(ciphertext, "fedcba9876543210"); err == nil && bytes.Equal(decoded, plaintext) {
+		t.Error("Expected a wrong key not to decrypt the value")
+	}
+	if _, err := Decode([]byte("short"), key); !errors.Is(err, ErrCorruptData) {
+		t.Errorf("Expected ErrCorruptData, but got: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package configstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState 表示 WithCircuitBreaker 设置的断路器的状态，由 Stats 返回
+type CircuitState uint8
+
+const (
+	// CircuitClosed 表示存储后端正常，所有请求都会访问后端。没有设置 WithCircuitBreaker 时总是 CircuitClosed。
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 表示连续失败的次数达到阈值，请求不再访问后端，直接返回 ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen 表示断路器打开的时间已经超过 timeout，下一个请求会作为探测访问后端
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", uint8(s))
+	}
+}
+
+// WithCircuitBreaker 统计存储后端连续读写失败的次数，达到 threshold 次后断路器打开，
+// 之后的读写不再访问后端，直接返回 ErrCircuitOpen，避免文件所在的 NFS 等存储不可用时每次请求都阻塞在 I/O 上。
+// 同时启用 WithCache 时，读取返回最近一次成功读取的配置。
+// 断路器打开 timeout 之后进入半开状态，下一次读写作为探测访问后端，成功时关闭断路器，失败时重新打开。
+// WithRetry 的多次重试只算一次失败。threshold 小于 1 或 timeout 不是正数时 New 返回 ErrInvalidOption。
+func WithCircuitBreaker(threshold int, timeout time.Duration) Option {
+	return func(c *storeConfig) {
+		c.circuitThreshold = threshold
+		c.circuitTimeout = timeout
+		c.circuitBreaker = true
+	}
+}
+
+func checkCircuitBreaker(cfg *storeConfig) error {
+	if cfg.circuitBreaker && (cfg.circuitThreshold < 1 || cfg.circuitTimeout <= 0) {
+		return fmt.Errorf("%w: circuit breaker requires a positive threshold and timeout", ErrInvalidOption)
+	}
+	return nil
+}
+
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	timeout   time.Duration
+	failures  int
+	open      bool
+	openedAt  time.Time
+	// 半开状态下是否已经有请求在探测
+	probing bool
+}
+
+// 没有设置 WithCircuitBreaker 时返回 nil
+func newCircuitBreaker(cfg *storeConfig) *circuitBreaker {
+	if !cfg.circuitBreaker {
+		return nil
+	}
+	return &circuitBreaker{threshold: cfg.circuitThreshold, timeout: cfg.circuitTimeout}
+}
+
+func (b *circuitBreaker) state() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stateLocked()
+}
+
+func (b *circuitBreaker) stateLocked() CircuitState {
+	switch {
+	case !b.open:
+		return CircuitClosed
+	case b.probing || time.Since(b.openedAt) >= b.timeout:
+		return CircuitHalfOpen
+	default:
+		return CircuitOpen
+	}
+}
+
+// 断路器打开时返回 ErrCircuitOpen；半开状态下只允许一个探测请求
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stateLocked() == CircuitClosed {
+		return nil
+	}
+	if b.stateLocked() == CircuitOpen || b.probing {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// 记录一次后端读写的结果
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if err == nil {
+		b.failures, b.open = 0, false
+		return
+	}
+	b.failures++
+	if b.open || b.failures >= b.threshold {
+		b.open, b.openedAt = true, time.Now()
+	}
+}
+
+// 经过断路器访问存储后端，失败时按 WithRetry 的设置重试
+func (cs *ConfigStore[T]) backendIO(ctx context.Context, fn func() error) error {
+	if err := cs.breaker.allow(); err != nil {
+		return err
+	}
+	err := cs.withRetry(ctx, fn)
+	cs.breaker.record(err)
+	return err
+}
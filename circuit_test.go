@@ -0,0 +1,93 @@
+package configstore
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	backend := &flakyBackend{err: errors.New("input/output error")}
+	cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"), WithCache(), WithCircuitBreaker(2, 50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "v1"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 连续失败两次后断路器打开
+	backend.failures = math.MaxInt
+	if err := cs.SaveConfig(myConfig{Username: "v2"}); err == nil {
+		t.Fatal("Expected save to fail")
+	}
+	if _, err := cs.LoadConfig(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected a backend error, but got: %v", err)
+	}
+	if stats, err := cs.Stats(); err != nil || stats.Circuit != CircuitOpen {
+		t.Errorf("Expected circuit to be open, but got: %v, %v", stats.Circuit, err)
+	}
+
+	// 断路器打开时不访问后端，返回最近一次成功读取的配置
+	calls := backend.calls
+	config, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil || config.Username != "v1" {
+		t.Errorf("Expected cached username v1, but got: %+v, %v", config, err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "v2"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, but got: %v", err)
+	}
+	if backend.calls != calls {
+		t.Errorf("Expected no backend access, but got %d calls", backend.calls-calls)
+	}
+
+	// 超过 timeout 后进入半开状态，探测成功时关闭断路器
+	time.Sleep(60 * time.Millisecond)
+	if stats, _ := cs.Stats(); stats.Circuit != CircuitHalfOpen {
+		t.Errorf("Expected circuit to be half-open, but got: %v", stats.Circuit)
+	}
+	backend.failures = backend.calls
+	if err := cs.SaveConfig(myConfig{Username: "v2"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if stats, _ := cs.Stats(); stats.Circuit != CircuitClosed {
+		t.Errorf("Expected circuit to be closed, but got: %v", stats.Circuit)
+	}
+	if config, err := cs.LoadConfig(); err != nil || config.Username != "v2" {
+		t.Errorf("Expected username v2, but got: %+v, %v", config, err)
+	}
+}
+
+func TestWithCircuitBreakerNoCache(t *testing.T) {
+	backend := &flakyBackend{err: errors.New("input/output error"), failures: math.MaxInt}
+	cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"), WithCircuitBreaker(1, 20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected a backend error, but got: %v", err)
+	}
+	config, err := cs.LoadConfigOrDefault(myConfig{Username: "default"})
+	if !errors.Is(err, ErrCircuitOpen) || config.Username != "default" {
+		t.Errorf("Expected default config and ErrCircuitOpen, but got: %+v, %v", config, err)
+	}
+
+	// 探测失败时重新打开
+	time.Sleep(30 * time.Millisecond)
+	if _, err := cs.LoadConfig(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected probe to reach the backend, but got: %v", err)
+	}
+	if stats, _ := cs.Stats(); stats.Circuit != CircuitOpen {
+		t.Errorf("Expected circuit to be open, but got: %v", stats.Circuit)
+	}
+
+	for _, opt := range []Option{WithCircuitBreaker(0, time.Second), WithCircuitBreaker(1, 0)} {
+		if _, err := New[myConfig](WithBackend(NewMemoryBackend()), WithKey("0123456789abcdef"), opt); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption, but got: %v", err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// patchOp 是 RFC 6902 JSON Patch 中的一个操作
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func runDiff(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var sf storeFlags
+	sf.registerKey(fs)
+	fileA := fs.String("a", "", "first encrypted config file")
+	fileB := fs.String("b", "", "second encrypted config file")
+	output := fs.String("output", "text", "output format: text or json-patch")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *fileA == "" || *fileB == "" {
+		return errors.New("-a and -b are required")
+	}
+
+	a, err := loadJSON(&sf, *fileA)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *fileA, err)
+	}
+	b, err := loadJSON(&sf, *fileB)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *fileB, err)
+	}
+
+	// 文本输出在 cmp 的 diff 之前列出所有变化的路径
+	var ops []patchOp
+	diffJSON(&ops, "", a, b)
+	switch strings.ToLower(*output) {
+	case "text":
+		if len(ops) == 0 {
+			return nil
+		}
+		for _, op := range ops {
+			fmt.Fprintf(stdout, "%s %s\n", op.Op, op.Path)
+		}
+		fmt.Fprintf(stdout, "\n%s", cmp.Diff(a, b))
+		return nil
+	case "json-patch":
+		if ops == nil {
+			ops = []patchOp{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ops)
+	default:
+		return fmt.Errorf("unknown output %q, expected text or json-patch", *output)
+	}
+}
+
+// 解密 file 并解析其中的 JSON
+func loadJSON(sf *storeFlags, file string) (any, error) {
+	cs, err := sf.openFile(file)
+	if err != nil {
+		return nil, err
+	}
+	config, err := cs.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(config)
+}
+
+// 生成将 a 变为 b 的 JSON Patch 操作。对象逐个 key 比较，数组和其他值整体替换。
+func diffJSON(ops *[]patchOp, path string, a, b any) {
+	objA, okA := a.(map[string]any)
+	objB, okB := b.(map[string]any)
+	if !okA || !okB {
+		if !reflect.DeepEqual(a, b) {
+			*ops = append(*ops, patchOp{Op: "replace", Path: path, Value: patchValue(b)})
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(objA)+len(objB))
+	for k := range objA {
+		keys = append(keys, k)
+	}
+	for k := range objB {
+		if _, ok := objA[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := path + "/" + escapePointer(k)
+		va, inA := objA[k]
+		vb, inB := objB[k]
+		switch {
+		case !inB:
+			*ops = append(*ops, patchOp{Op: "remove", Path: p})
+		case !inA:
+			*ops = append(*ops, patchOp{Op: "add", Path: p, Value: patchValue(vb)})
+		default:
+			diffJSON(ops, p, va, vb)
+		}
+	}
+}
+
+// 操作中的值，null 同样需要输出
+func patchValue(v any) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// 按 RFC 6901 转义 JSON Pointer 中的 key
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 加密 config 写入 dir 中的 name，返回文件路径
+func writeConfig(t *testing.T, dir, name, config string) string {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	if err := run([]string{"write", "-file=" + file, "-key=0123456789abcdef", "-input=-"}, strings.NewReader(config), nil); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	return file
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	a := writeConfig(t, dir, "prod.data", `{"name": "app", "server": {"port": 80, "host": "prod"}, "debug": true}`)
+	b := writeConfig(t, dir, "staging.data", `{"name": "app", "server": {"port": 8080, "host": "prod"}, "tls/cert": null}`)
+
+	var out bytes.Buffer
+	if err := run([]string{"diff", "-a=" + a, "-b=" + b, "-key=0123456789abcdef"}, nil, &out); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, want := range []string{"remove /debug", "replace /server/port", "add /tls~1cert", "8080"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, but got: %s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := run([]string{"diff", "-a=" + a, "-b=" + b, "-key=0123456789abcdef", "-output=json-patch"}, nil, &out); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var patch []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &patch); err != nil {
+		t.Fatalf("Expected valid JSON, but got: %v", err)
+	}
+	expected := []map[string]any{
+		{"op": "remove", "path": "/debug"},
+		{"op": "replace", "path": "/server/port", "value": float64(8080)},
+		{"op": "add", "path": "/tls~1cert", "value": nil},
+	}
+	if !reflect.DeepEqual(patch, expected) {
+		t.Errorf("Expected patch %v, but got: %v", expected, patch)
+	}
+
+	// 相同的文件没有差异
+	out.Reset()
+	if err := run([]string{"diff", "-a=" + a, "-b=" + a, "-key=0123456789abcdef", "-output=json-patch"}, nil, &out); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("Expected an empty patch, but got: %s", out.String())
+	}
+}
+
+func TestDiffMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	a := writeConfig(t, dir, "prod.data", `{"name": "app"}`)
+	if err := run([]string{"diff", "-a=" + a, "-key=0123456789abcdef"}, nil, nil); err == nil {
+		t.Error("Expected an error without -b")
+	}
+	missing := filepath.Join(dir, "missing.data")
+	if err := run([]string{"diff", "-a=" + a, "-b=" + missing, "-key=0123456789abcdef"}, nil, nil); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Expected missing file not to be created, but got: %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+// configstore 是读写加密配置文件的命令行工具：
+//
+//	configstore read -file=x.data -key=... [-format=json|yaml|toml]
+//	configstore write -file=x.data -key=... -input=config.json
+//	configstore diff -a=prod.data -b=staging.data -key=... [-output=text|json-patch]
+//	configstore verify -file=x.data -key=...
+//
+// key 依次从 -key、-key-file 和环境变量 CONFIGSTORE_KEY 中获取。
+// 未指定 -cipher 时使用文件头部记录的加密模式，新文件使用 CBC。
+// 配置以 JSON 格式保存，因此可以读写任意结构的配置。
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/JanusHuang/configstore"
+	"gopkg.in/yaml.v3"
+)
+
+// 未通过参数指定 key 时读取的环境变量
+const keyEnv = "CONFIGSTORE_KEY"
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		// verify 已经输出了结果
+		if !errors.Is(err, errVerifyFailed) {
+			fmt.Fprintln(os.Stderr, "configstore:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: configstore read|write|diff|verify [flags]")
+	}
+	switch args[0] {
+	case "read":
+		return runRead(args[1:], stdout)
+	case "write":
+		return runWrite(args[1:], stdin)
+	case "diff":
+		return runDiff(args[1:], stdout)
+	case "verify":
+		return runVerify(args[1:], stdout)
+	default:
+		return fmt.Errorf("unknown command %q, expected read, write, diff or verify", args[0])
+	}
+}
+
+// 子命令共用的参数
+type storeFlags struct {
+	file    string
+	key     string
+	keyFile string
+	cipher  string
+}
+
+func (f *storeFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.file, "file", "", "encrypted config file")
+	f.registerKey(fs)
+}
+
+// 注册 key 和加密模式相关的参数，diff 使用 -a 和 -b 代替 -file
+func (f *storeFlags) registerKey(fs *flag.FlagSet) {
+	fs.StringVar(&f.key, "key", "", "encryption key (default $"+keyEnv+")")
+	fs.StringVar(&f.keyFile, "key-file", "", "file containing the encryption key")
+	fs.StringVar(&f.cipher, "cipher", "", "cipher mode: cbc, gcm, chacha20poly1305, xchacha20poly1305 or none (default from the file header, cbc for new files)")
+}
+
+// 按参数打开 ConfigStore，文件不存在时不会创建
+func (f *storeFlags) open() (*configstore.ConfigStore[json.RawMessage], error) {
+	if f.file == "" {
+		return nil, errors.New("-file is required")
+	}
+	return f.openFile(f.file)
+}
+
+func (f *storeFlags) openFile(file string) (*configstore.ConfigStore[json.RawMessage], error) {
+	key, err := f.readKey()
+	if err != nil {
+		return nil, err
+	}
+	mode, err := f.cipherMode(file)
+	if err != nil {
+		return nil, err
+	}
+	return configstore.New[json.RawMessage](
+		configstore.WithBackend(configstore.NewFileBackend(file)),
+		configstore.WithKey(key),
+		configstore.WithCipherMode(mode),
+	)
+}
+
+// 未指定 -cipher 时使用文件头部记录的加密模式，文件不存在时使用默认的 CBC
+func (f *storeFlags) cipherMode(file string) (configstore.CipherMode, error) {
+	if f.cipher != "" {
+		return parseCipherMode(f.cipher)
+	}
+	mode, err := configstore.FileCipherMode(file)
+	if errors.Is(err, configstore.ErrFileNotFound) {
+		return configstore.CipherModeCBC, nil
+	}
+	return mode, err
+}
+
+func (f *storeFlags) readKey() (string, error) {
+	if f.key != "" {
+		return f.key, nil
+	}
+	if f.keyFile != "" {
+		data, err := os.ReadFile(f.keyFile)
+		if err != nil {
+			return "", err
+		}
+		// 忽略文件末尾的换行
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if key := os.Getenv(keyEnv); key != "" {
+		return key, nil
+	}
+	return "", fmt.Errorf("a key is required: use -key, -key-file or $%s", keyEnv)
+}
+
+func parseCipherMode(s string) (configstore.CipherMode, error) {
+	switch strings.ToLower(s) {
+	case "cbc":
+		return configstore.CipherModeCBC, nil
+	case "gcm":
+		return configstore.CipherModeGCM, nil
+	case "chacha20poly1305":
+		return configstore.CipherModeChaCha20Poly1305, nil
+	case "xchacha20poly1305":
+		return configstore.CipherModeXChaCha20Poly1305, nil
+	case "none":
+		return configstore.CipherModeNone, nil
+	default:
+		return 0, fmt.Errorf("unknown cipher mode %q", s)
+	}
+}
+
+func runRead(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("read", flag.ContinueOnError)
+	var sf storeFlags
+	sf.register(fs)
+	format := fs.String("format", "json", "output format: json, yaml or toml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cs, err := sf.open()
+	if err != nil {
+		return err
+	}
+	config, err := cs.LoadConfig()
+	if err != nil {
+		return err
+	}
+	out, err := formatConfig(config, *format)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(out)
+	return err
+}
+
+func runWrite(args []string, stdin io.Reader) error {
+	fs := flag.NewFlagSet("write", flag.ContinueOnError)
+	var sf storeFlags
+	sf.register(fs)
+	input := fs.String("input", "", "JSON file to encrypt, - for stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		return errors.New("-input is required")
+	}
+
+	var data []byte
+	var err error
+	if *input == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(*input)
+	}
+	if err != nil {
+		return err
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("%s is not valid JSON", *input)
+	}
+
+	cs, err := sf.open()
+	if err != nil {
+		return err
+	}
+	// 压缩掉输入中的空白，保存的内容与格式无关
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return err
+	}
+	return cs.SaveConfig(json.RawMessage(compact.Bytes()))
+}
+
+// 将 JSON 配置转换为指定的输出格式
+func formatConfig(config json.RawMessage, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, config, "", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	case "yaml":
+		v, err := decodeJSON(config)
+		if err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(v)
+	case "toml":
+		v, err := decodeJSON(config)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := v.(map[string]any); !ok {
+			return nil, errors.New("toml output requires a JSON object")
+		}
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected json, yaml or toml", format)
+	}
+}
+
+// 解析 JSON，整数保持为 int64，避免在 YAML/TOML 中输出为浮点数
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(v), nil
+}
+
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeNumbers(e)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JanusHuang/configstore"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.data")
+	input := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(input, []byte(`{"name": "app", "port": 8080, "tags": ["a", "b"]}`), 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := run([]string{"write", "-file=" + file, "-key=0123456789abcdef", "-input=" + input}, nil, nil); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if bytes.Contains(data, []byte("app")) {
+		t.Error("Expected file to be encrypted")
+	}
+
+	cases := map[string]string{
+		"json": "\"port\": 8080",
+		"yaml": "port: 8080",
+		"toml": "port = 8080",
+	}
+	for format, want := range cases {
+		var out bytes.Buffer
+		if err := run([]string{"read", "-file=" + file, "-key=0123456789abcdef", "-format=" + format}, nil, &out); err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", format, err)
+		}
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%s: expected output to contain %q, but got: %s", format, want, out.String())
+		}
+	}
+}
+
+func TestKeySources(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.data")
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("0123456789abcdef\n"), 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 从标准输入读取配置，从 key 文件读取 key
+	stdin := strings.NewReader(`{"name":"app"}`)
+	if err := run([]string{"write", "-file=" + file, "-key-file=" + keyFile, "-input=-"}, stdin, nil); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 从环境变量读取 key
+	t.Setenv(keyEnv, "0123456789abcdef")
+	var out bytes.Buffer
+	if err := run([]string{"read", "-file=" + file}, nil, &out); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !strings.Contains(out.String(), `"name": "app"`) {
+		t.Errorf("Expected decrypted config, but got: %s", out.String())
+	}
+
+	t.Setenv(keyEnv, "")
+	if err := run([]string{"read", "-file=" + file}, nil, &out); err == nil {
+		t.Error("Expected an error without a key")
+	}
+}
+
+func TestWriteInvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	stdin := strings.NewReader(`{"name":`)
+	err := run([]string{"write", "-file=" + filepath.Join(dir, "app.data"), "-key=0123456789abcdef", "-input=-"}, stdin, nil)
+	if err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.data")
+	stdin := strings.NewReader(`{"name":"app"}`)
+	if err := run([]string{"write", "-file=" + file, "-key=0123456789abcdef", "-cipher=gcm", "-input=-"}, stdin, nil); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"verify", "-file=" + file, "-key=0123456789abcdef", "-cipher=gcm"}, nil, &out); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if want := "OK: " + file + " (version=1, cipher=AES-GCM, saved="; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("Expected output to start with %q, but got: %s", want, out.String())
+	}
+	if strings.Contains(out.String(), "app\"") || strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("Expected a one-line summary without the config, but got: %s", out.String())
+	}
+
+	out.Reset()
+	err := run([]string{"verify", "-file=" + file, "-key=fedcba9876543210", "-cipher=gcm"}, nil, &out)
+	if !errors.Is(err, errVerifyFailed) {
+		t.Errorf("Expected errVerifyFailed, but got: %v", err)
+	}
+	if want := "ERROR: " + file + ": decryption failed"; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("Expected output to start with %q, but got: %s", want, out.String())
+	}
+}
+
+func TestReadCipherFromHeader(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.data")
+	key := "0123456789abcdef0123456789abcdef"
+	stdin := strings.NewReader(`{"name":"app"}`)
+	if err := run([]string{"write", "-file=" + file, "-key=" + key, "-cipher=xchacha20poly1305", "-input=-"}, stdin, nil); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 未指定 -cipher 时从文件头部读取加密模式
+	var out bytes.Buffer
+	if err := run([]string{"read", "-file=" + file, "-key=" + key}, nil, &out); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !strings.Contains(out.String(), `"name": "app"`) {
+		t.Errorf("Expected output to contain the config, but got: %s", out.String())
+	}
+
+	// 再次写入时保持文件原来的加密模式
+	stdin = strings.NewReader(`{"name":"app2"}`)
+	if err := run([]string{"write", "-file=" + file, "-key=" + key, "-input=-"}, stdin, nil); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if mode, err := configstore.FileCipherMode(file); err != nil || mode != configstore.CipherModeXChaCha20Poly1305 {
+		t.Errorf("Expected XChaCha20-Poly1305, but got: %v, %v", mode, err)
+	}
+
+	// 显式指定的 -cipher 优先于文件头部
+	err := run([]string{"read", "-file=" + file, "-key=" + key, "-cipher=gcm"}, nil, &out)
+	if !errors.Is(err, configstore.ErrStoreMismatch) {
+		t.Errorf("Expected ErrStoreMismatch for an explicit -cipher, but got: %v", err)
+	}
+}
+
+func TestVerifyCipherFromHeader(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gcm.data")
+	cs, err := configstore.New[map[string]string](configstore.WithFile(file), configstore.WithKey("0123456789abcdef"), configstore.WithCipherMode(configstore.CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(map[string]string{"name": "app"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 未指定 -cipher 时从文件头部读取加密模式
+	var out bytes.Buffer
+	if err := run([]string{"verify", "-file=" + file, "-key=0123456789abcdef"}, nil, &out); err != nil {
+		t.Fatalf("Expected no error, but got: %v, output: %s", err, out.String())
+	}
+	if want := "OK: " + file + " (version=1, cipher=AES-GCM, saved="; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("Expected output to start with %q, but got: %s", want, out.String())
+	}
+}
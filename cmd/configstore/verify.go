@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/JanusHuang/configstore"
+)
+
+// verify 检查失败，结果已经输出到标准输出
+var errVerifyFailed = errors.New("verify failed")
+
+// 检查文件能否解密和解析，不输出配置内容。成功时输出
+//
+//	OK: app.data (version=1, cipher=AES-GCM, saved=2024-01-15T10:00:00Z)
+//
+// 失败时输出 ERROR: 和原因，并以状态码 1 退出。
+func runVerify(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	var sf storeFlags
+	sf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	stats, err := verify(&sf)
+	if err != nil {
+		fmt.Fprintf(stdout, "ERROR: %s: %s\n", sf.file, verifyReason(err))
+		return errVerifyFailed
+	}
+	// 没有记录保存时间时使用文件的修改时间
+	saved := stats.SavedAt
+	if saved.IsZero() {
+		saved = stats.ModTime
+	}
+	fmt.Fprintf(stdout, "OK: %s (version=%d, cipher=%v, saved=%s)\n", sf.file, stats.FormatVersion, stats.CipherMode, saved.UTC().Format(time.RFC3339))
+	return nil
+}
+
+func verify(sf *storeFlags) (configstore.ConfigStats, error) {
+	cs, err := sf.open()
+	if err != nil {
+		return configstore.ConfigStats{}, err
+	}
+	defer cs.Close()
+	if _, err := cs.LoadConfig(); err != nil {
+		return configstore.ConfigStats{}, err
+	}
+	return cs.Stats()
+}
+
+// 去掉 *StoreError 中的操作和文件名，文件名已经在输出中
+func verifyReason(err error) string {
+	var se *configstore.StoreError
+	if errors.As(err, &se) {
+		err = se.Err
+	}
+	return strings.TrimPrefix(err.Error(), "configstore: ")
+}
@@ -0,0 +1,167 @@
+package configstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// 解压后数据的大小上限，未启用完整性校验时压缩数据可能被篡改，避免少量数据解压出大量内容耗尽内存
+const maxDecompressedSize = 64 << 20
+
+// Compression 表示序列化之后、加密之前使用的压缩算法
+type Compression uint8
+
+const (
+	// CompressionNone 表示不压缩，是默认值
+	CompressionNone Compression = iota
+	// CompressionGzip 使用 compress/gzip
+	CompressionGzip
+	// CompressionZstd 使用 github.com/klauspost/compress/zstd，压缩率和速度通常都优于 gzip
+	CompressionZstd
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return "unknown"
+	}
+}
+
+// 对应的文件头部标志位
+func (c Compression) flag() byte {
+	switch c {
+	case CompressionGzip:
+		return flagGzip
+	case CompressionZstd:
+		return flagZstd
+	default:
+		return 0
+	}
+}
+
+// 根据文件头部的标志位判断使用的压缩算法
+func compressionFromFlags(flags byte) Compression {
+	switch {
+	case flags&flagGzip != 0:
+		return CompressionGzip
+	case flags&flagZstd != 0:
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
+
+// 校验压缩级别是否有效
+func (c Compression) checkLevel(level int) error {
+	switch c {
+	case CompressionNone:
+		return nil
+	case CompressionGzip:
+		if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+			return fmt.Errorf("%w: invalid gzip compression level %d", ErrInvalidOption, level)
+		}
+		return nil
+	case CompressionZstd:
+		// 与 zstd 命令行一致的 1-22 级
+		if level < 1 || level > 22 {
+			return fmt.Errorf("%w: invalid zstd compression level %d", ErrInvalidOption, level)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w compression %d", ErrUnsupported, c)
+	}
+}
+
+func (c Compression) compress(data []byte, level int) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return data, nil
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w, err := gzip.NewWriterLevel(&buf, level)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		w, err := zstdEncoder(level)
+		if err != nil {
+			return nil, err
+		}
+		return w.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("%w compression %d", ErrUnsupported, c)
+	}
+}
+
+func (c Compression) decompress(data []byte) ([]byte, error) {
+	// 空数据不需要解压
+	if c == CompressionNone || len(data) == 0 {
+		return data, nil
+	}
+	switch c {
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptData, err)
+		}
+		defer r.Close()
+		plaintext, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptData, err)
+		}
+		if len(plaintext) > maxDecompressedSize {
+			return nil, fmt.Errorf("%w: decompressed data exceeds %d bytes", ErrCorruptData, maxDecompressedSize)
+		}
+		return plaintext, nil
+	case CompressionZstd:
+		r, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := r.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptData, err)
+		}
+		return plaintext, nil
+	default:
+		return nil, fmt.Errorf("%w compression %d", ErrUnsupported, c)
+	}
+}
+
+// zstd 的编码器和解码器创建开销较大，EncodeAll/DecodeAll 可以并发使用，因此全局复用
+var (
+	zstdEncoders sync.Map // level -> *zstd.Encoder
+	zstdDecoder  = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	})
+)
+
+func zstdEncoder(level int) (*zstd.Encoder, error) {
+	if w, ok := zstdEncoders.Load(level); ok {
+		return w.(*zstd.Encoder), nil
+	}
+	w, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := zstdEncoders.LoadOrStore(level, w)
+	return actual.(*zstd.Encoder), nil
+}
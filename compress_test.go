@@ -0,0 +1,204 @@
+package configstore
+
+import (
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type allowListConfig struct {
+	Hosts []string `json:"hosts"`
+}
+
+func newAllowList(n int) allowListConfig {
+	config := allowListConfig{}
+	for i := 0; i < n; i++ {
+		config.Hosts = append(config.Hosts, strings.Repeat("host.example.com", 4))
+	}
+	return config
+}
+
+func TestGzipCompression(t *testing.T) {
+	dir := t.TempDir()
+	key := "0123456789abcdef"
+	config := newAllowList(100)
+
+	plain, err := NewConfigStore[allowListConfig](filepath.Join(dir, "plain.data"), key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	compressed, err := NewConfigStore[allowListConfig](filepath.Join(dir, "gzip.data"), key, WithCompression(gzip.BestCompression))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, cs := range []*ConfigStore[allowListConfig]{plain, compressed} {
+		if err := cs.SaveConfig(config); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	plainInfo, _ := os.Stat(filepath.Join(dir, "plain.data"))
+	gzipInfo, _ := os.Stat(filepath.Join(dir, "gzip.data"))
+	if gzipInfo.Size() >= plainInfo.Size() {
+		t.Errorf("Expected compressed file (%d bytes) to be smaller than plain file (%d bytes)", gzipInfo.Size(), plainInfo.Size())
+	}
+
+	loadConfig, err := compressed.LoadConfigOrDefault(allowListConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !reflect.DeepEqual(loadConfig, config) {
+		t.Errorf("Expected compressed config to round-trip")
+	}
+}
+
+func TestCompressionReadsUncompressedFile(t *testing.T) {
+	// 启用压缩后仍可以读取未压缩的文件
+	filename := filepath.Join(t.TempDir(), "plain.data")
+	key := "0123456789abcdef"
+	plain, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+	if err := plain.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	compressed, err := NewConfigStore[myConfig](filename, key, WithCompression(gzip.DefaultCompression))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := compressed.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestCompressionEmptyData(t *testing.T) {
+	data, err := CompressionGzip.decompress(nil)
+	if err != nil || len(data) != 0 {
+		t.Errorf("Expected empty data to decompress to nothing, but got: %v, %v", data, err)
+	}
+
+	// 新创建的空文件返回默认配置对应的错误，而不是 panic
+	filename := filepath.Join(t.TempDir(), "empty.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithCompression(gzip.DefaultCompression))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defaultConfig := myConfig{Username: "default"}
+	loadConfig, _ := cs.LoadConfigOrDefault(defaultConfig)
+	if loadConfig != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v", loadConfig)
+	}
+}
+
+func TestInvalidCompressionLevel(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "gzip.data")
+	if _, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithCompression(42)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected an error for an invalid compression level, but got nil")
+	}
+}
+
+func TestZstdCompression(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "zstd.data")
+	cs, err := NewConfigStore[allowListConfig](filename, "0123456789abcdef", WithCompressionAlgorithm(CompressionZstd, 3))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := newAllowList(100)
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(allowListConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !reflect.DeepEqual(loadConfig, config) {
+		t.Errorf("Expected compressed config to round-trip")
+	}
+
+	data, _ := os.ReadFile(filename)
+	header, _, _ := parseFileHeader(data)
+	if compressionFromFlags(header.flags) != CompressionZstd {
+		t.Errorf("Expected header to record zstd compression, but got flags: %08b", header.flags)
+	}
+
+	if _, err := NewConfigStore[allowListConfig](filename, "0123456789abcdef", WithCompressionAlgorithm(CompressionZstd, 0)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected an error for an invalid zstd level, but got nil")
+	}
+}
+
+// 大约 1 KB 的 JSON 配置
+func benchmarkCompressionConfig() allowListConfig {
+	return newAllowList(16)
+}
+
+func BenchmarkCompression(b *testing.B) {
+	cases := []struct {
+		name string
+		opts []Option
+	}{
+		{"json", nil},
+		{"gzip", []Option{WithCompression(gzip.DefaultCompression)}},
+		{"zstd", []Option{WithCompressionAlgorithm(CompressionZstd, 3)}},
+	}
+	config := benchmarkCompressionConfig()
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			cs, err := NewConfigStore[allowListConfig]("", "0123456789abcdef", append(c.opts, WithBackend(NewMemoryBackend()))...)
+			if err != nil {
+				b.Fatalf("Expected no error, but got: %v", err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := cs.SaveConfig(config); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := cs.LoadConfigOrDefault(allowListConfig{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			data, _ := cs.backend.Read()
+			b.ReportMetric(float64(len(data)), "bytes/file")
+		})
+	}
+}
+
+func TestGzipDecompressionBomb(t *testing.T) {
+	// 少量压缩数据解压后超过上限时视为损坏
+	bomb, err := CompressionGzip.compress(make([]byte, maxDecompressedSize+1), gzip.BestCompression)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(bomb) > 1<<20 {
+		t.Fatalf("Expected a small compressed payload, but got %d bytes", len(bomb))
+	}
+	if _, err := CompressionGzip.decompress(bomb); !errors.Is(err, ErrCorruptData) {
+		t.Errorf("Expected ErrCorruptData, but got: %v", err)
+	}
+}
+
+func TestZstdDecompressionBomb(t *testing.T) {
+	// 少量压缩数据解压后超过上限时视为损坏
+	bomb, err := CompressionZstd.compress(make([]byte, maxDecompressedSize+1), 19)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(bomb) > 1<<20 {
+		t.Fatalf("Expected a small compressed payload, but got %d bytes", len(bomb))
+	}
+	if _, err := CompressionZstd.decompress(bomb); !errors.Is(err, ErrCorruptData) {
+		t.Errorf("Expected ErrCorruptData, but got: %v", err)
+	}
+}
@@ -2,104 +2,990 @@ package configstore
 
 import (
 	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
+	"context"
 	"crypto/rand"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
 )
 
 type ConfigStore[T any] struct {
-	filename string
-	key      string
-	mu       sync.Mutex
+	storeConfig
+	mu     ctxRWMutex
+	onSave []func(T)
+	onLoad []func(T)
+	// storeConfig 中同名的 validator 字段保存的是未转换类型的值
+	validator Validator[T]
+	// WithJSONSchemaValidation 编译后的 schema
+	schema *jsonschema.Schema
+	// 带有 configstore:"encrypt" 标签的字段
+	fields fieldTree
+	// WithCache 缓存的配置，受 mu 保护
+	cached        T
+	cachedSavedAt time.Time
+	hasCached     bool
+	closed        bool
+	// WithMetrics 注册的指标，未设置时为 nil
+	metrics *storeMetrics
+	// WithRecoveryMode 恢复备份时使用，避免持有读锁的多个读取同时写入文件
+	recoverMu sync.Mutex
+	// NewEphemeralStore 创建的内存后端，Close 时清零
+	ephemeral *MemoryBackend
+	// 上一次读取或写入后配置文件的修改时间（UnixNano），0 表示还没有读取过或文件不存在
+	lastLoadedAt atomic.Int64
+	// WithCircuitBreaker 设置的断路器，未设置时为 nil
+	breaker *circuitBreaker
+	// 同时启用 WithCache 和 WithCircuitBreaker 时，最近一次成功读取的配置，断路器打开时返回，受 mu 保护
+	stale    T
+	hasStale bool
+}
+
+// New 使用 Option 创建 ConfigStore，通过 WithFile 或 WithBackend 指定存储位置，
+// 通过 WithKey 或 WithKeyProvider 指定加密 key：
+//
+//	cs, err := configstore.New[Config](
+//		configstore.WithFile("config.data"),
+//		configstore.WithKey(key),
+//		configstore.WithCipherMode(configstore.CipherModeGCM),
+//	)
+func New[T any](opts ...Option) (*ConfigStore[T], error) {
+	cfg := defaultStoreConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if err := cfg.readKeyFile(); err != nil {
+		return nil, newStoreError("open", cfg.filename, err)
+	}
+	// 原始 key 不需要派生
+	cfg.kdf = KDFNone
+
+	// 检查 key 的长度是否符合要求，使用 KeyProvider 时在每次获取 key 后检查
+	if cfg.keyProvider == nil {
+		if err := cfg.cipherMode.checkKeyLen(len(cfg.key)); err != nil {
+			return nil, newStoreError("open", cfg.filename, err)
+		}
+	}
+
+	return newConfigStore[T](cfg)
+}
+
+// MustNew 与 New 相同，但创建失败时 panic，panic 的值是包含文件名和原因的 *StoreError。
+// 只应在程序启动时使用，例如初始化包级变量：
+//
+//	var store = configstore.MustNew[AppConfig](configstore.WithFile("app.data"), configstore.WithKey(key))
+//
+// 运行中创建 ConfigStore 时使用 New 并处理返回的错误。
+func MustNew[T any](opts ...Option) *ConfigStore[T] {
+	cs, err := New[T](opts...)
+	if err != nil {
+		panic(err)
+	}
+	return cs
+}
+
+// MustNewConfigStore 与 MustNew 相同，使用 filename 和 key 创建 ConfigStore，创建失败时 panic。
+// 只应在程序启动时使用。
+func MustNewConfigStore[T any](filename string, key string, opts ...Option) *ConfigStore[T] {
+	return MustNew[T](append([]Option{WithFile(filename), WithKey(key)}, opts...)...)
+}
+
+// NewEphemeralStore 创建只在进程运行期间存在的 ConfigStore，配置保存在 MemoryBackend 中，不读写文件。
+// 内存中的数据仍然是加密的，Close 时清零。适用于只在进程生命周期内需要配置的命令行工具和 lambda 函数。
+func NewEphemeralStore[T any](key string, opts ...Option) (*ConfigStore[T], error) {
+	backend := NewMemoryBackend()
+	cs, err := New[T](append([]Option{WithBackend(backend), WithKey(key)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	cs.ephemeral = backend
+	return cs, nil
 }
 
 // 为函数添加泛型约束，这里使用空接口作为通用约束，表示可以是任意类型
-func NewConfigStore[T any](filename string, key string) (*ConfigStore[T], error) {
-	// 检查 key 的长度是否符合要求
-	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
-		return nil, errors.New("key length must be 16 or 24 or 32")
+//
+// Deprecated: 使用 New 以及 WithFile、WithKey，NewConfigStore 将在下一个版本中移除。
+func NewConfigStore[T any](filename string, key string, opts ...Option) (*ConfigStore[T], error) {
+	return New[T](append([]Option{WithFile(filename), WithKey(key)}, opts...)...)
+}
+
+// Open 使用密码打开 filename 对应的 ConfigStore，是推荐使用的构造函数：加密 key 由密码派生为 32 字节，
+// 调用方不需要关心不同加密模式对 key 长度的要求。默认使用 PBKDF2，可以通过 WithKDF 等 Option 调整。
+// 需要自行管理原始 key 时使用 New 以及 WithKey。
+func Open[T any](filename string, password string, opts ...Option) (*ConfigStore[T], error) {
+	return NewConfigStoreFromPassword[T](filename, password, opts...)
+}
+
+// NewConfigStoreFromPassword 使用密码创建 ConfigStore，加密 key 由密码派生（默认 PBKDF2-HMAC-SHA256）。
+// 每次保存都会重新生成随机盐，盐和派生参数保存在文件头部。
+func NewConfigStoreFromPassword[T any](filename string, password string, opts ...Option) (*ConfigStore[T], error) {
+	cfg := defaultStoreConfig()
+	cfg.kdf = KDFPBKDF2
+	cfg.filename = filename
+	cfg.key = []byte(password)
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if err := cfg.readKeyFile(); err != nil {
+		return nil, newStoreError("open", cfg.filename, err)
+	}
+
+	if len(cfg.key) == 0 && cfg.keyProvider == nil {
+		return nil, newStoreError("open", cfg.filename, errEmptyPassword)
+	}
+	if cfg.kdf == KDFNone {
+		return nil, newStoreError("open", cfg.filename, fmt.Errorf("%w: a kdf is required for password based stores", ErrInvalidOption))
+	}
+	// 提前校验派生参数，避免到保存时才发现配置错误
+	if _, err := newKDFParams(&cfg, rand.Reader); err != nil {
+		return nil, newStoreError("open", cfg.filename, err)
+	}
+	if err := cfg.cipherMode.checkKeyLen(derivedKeySize); err != nil {
+		return nil, newStoreError("open", cfg.filename, err)
+	}
+
+	return newConfigStore[T](cfg)
+}
+
+func newConfigStore[T any](cfg storeConfig) (*ConfigStore[T], error) {
+	cs, err := openConfigStore[T](cfg)
+	if err != nil {
+		return nil, newStoreError("open", cfg.filename, err)
+	}
+	return cs, nil
+}
+
+func openConfigStore[T any](cfg storeConfig) (*ConfigStore[T], error) {
+	if cfg.pathResolver != nil && cfg.backend == nil && cfg.filename != "" {
+		filename, err := resolveFilename(cfg.pathResolver, cfg.filename, cfg.dirMode)
+		if err != nil {
+			return nil, err
+		}
+		cfg.filename = filename
+	}
+	if err := cfg.compression.checkLevel(cfg.compressionLevel); err != nil {
+		return nil, err
+	}
+	if err := checkMigrators(&cfg); err != nil {
+		return nil, err
+	}
+	if err := checkEnvOverride[T](&cfg); err != nil {
+		return nil, err
+	}
+	if err := checkRetry(&cfg); err != nil {
+		return nil, err
+	}
+	if err := checkCircuitBreaker(&cfg); err != nil {
+		return nil, err
+	}
+	if cfg.format == FormatBinary {
+		if err := checkBinaryFormat[T](); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.codec == nil {
+		codec, err := cfg.format.codec()
+		if err != nil {
+			return nil, err
+		}
+		cfg.codec = codec
+	}
+
+	onSave, err := hooksOf[T](cfg.onSave)
+	if err != nil {
+		return nil, err
+	}
+	onLoad, err := hooksOf[T](cfg.onLoad)
+	if err != nil {
+		return nil, err
+	}
+	validator, err := validatorOf[T](cfg.validator)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := compileJSONSchema(cfg.jsonSchema)
+	if err != nil {
+		return nil, err
+	}
+	fields := encryptedFields(reflect.TypeOf((*T)(nil)).Elem())
+	if fields != nil && cfg.format != FormatJSON {
+		return nil, fmt.Errorf("%w: field encryption requires FormatJSON", ErrUnsupported)
+	}
+	metricsFile := cfg.filename
+	if fb, ok := cfg.backend.(*FileBackend); ok {
+		metricsFile = fb.filename
+	}
+	metrics, err := newStoreMetrics(cfg.metrics, metricsFile)
+	if err != nil {
+		return nil, err
+	}
+	cs := &ConfigStore[T]{storeConfig: cfg, onSave: onSave, onLoad: onLoad, validator: validator, schema: schema, fields: fields, metrics: metrics, breaker: newCircuitBreaker(&cfg)}
+
+	// 使用自定义后端时不需要处理文件
+	if cfg.backend != nil {
+		return cs, nil
 	}
+	if cfg.filename == "" {
+		return nil, fmt.Errorf("%w: a file or backend is required", ErrInvalidOption)
+	}
+	cs.backend = &FileBackend{filename: cfg.filename, mode: cfg.fileMode, sync: cfg.sync}
 
-	if !fileExists(filename) {
+	if !fileExists(cfg.filename) {
 		// 文件不存在，创建一个新的文件
-		err := createFile(filename)
+		err := createFile(cfg.filename, cfg.fileMode, cfg.dirMode)
 		if err != nil {
 			return nil, err
 		}
+	} else if err := restrictFileMode(cfg.filename, cfg.fileMode); err != nil {
+		return nil, err
 	}
 
-	return &ConfigStore[T]{filename: filename, key: key}, nil
+	return cs, nil
 }
 
+// 将 WithOnSave / WithOnLoad 注册的回调转换为具体类型
+func hooksOf[T any](hooks []any) ([]func(T), error) {
+	fns := make([]func(T), 0, len(hooks))
+	for _, hook := range hooks {
+		fn, ok := hook.(func(T))
+		if !ok {
+			var zero T
+			return nil, fmt.Errorf("%w: hook %T does not match config type %T", ErrInvalidOption, hook, zero)
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+// LoadConfigOrDefault 读取配置，还没有保存过配置时返回 defaultConfig。
+// 校验失败时返回读取到的配置和 ErrInvalidConfig，其他错误返回 defaultConfig 和对应的错误。
 func (cs *ConfigStore[T]) LoadConfigOrDefault(defaultConfig T) (T, error) {
-	cs.mu.Lock()
-	defer cs.mu.Unlock()
+	ctx, cancel := cs.defaultContext()
+	defer cancel()
+	return cs.LoadConfigContext(ctx, defaultConfig)
+}
 
-	// 读取文件内容
-	fileData, err := readFile(cs.filename)
+// LoadConfigContext 与 LoadConfigOrDefault 相同，ctx 被取消或超时时立即返回 ctx.Err()
+func (cs *ConfigStore[T]) LoadConfigContext(ctx context.Context, defaultConfig T) (T, error) {
+	config, err := cs.loadConfigContext(ctx)
+	if errors.Is(err, ErrNoConfig) {
+		// 默认配置同样可以被环境变量覆盖
+		config, err := cs.overrideEnv(defaultConfig)
+		if err != nil {
+			return defaultConfig, cs.wrapError("load", err)
+		}
+		return config, nil
+	}
+	return orDefault(config, err, defaultConfig)
+}
+
+// LoadConfig 读取配置，文件不存在或为空时返回 ErrNoConfig，便于区分“从未写入”和“数据损坏”
+func (cs *ConfigStore[T]) LoadConfig() (T, error) {
+	ctx, cancel := cs.defaultContext()
+	defer cancel()
+	return cs.loadConfigContext(ctx)
+}
+
+func (cs *ConfigStore[T]) loadConfigContext(ctx context.Context) (T, error) {
+	start := time.Now()
+	ctx, span := cs.startSpan(ctx, "configstore.Load")
+	config, err := runContext(ctx, func() (T, error) {
+		if !cs.cache {
+			// 读取只需要读锁，多个 goroutine 可以同时读取
+			if err := cs.mu.RLockContext(ctx); err != nil {
+				var zero T
+				return zero, err
+			}
+			defer cs.mu.RUnlock()
+			config, _, err := cs.loadConfig(ctx)
+			return config, err
+		}
+
+		if config, ok := cs.cachedConfig(); ok {
+			span.SetAttributes(attrCacheHit.Bool(true))
+			return config, nil
+		}
+		// 没有缓存时需要写锁更新缓存，获取写锁后其他 goroutine 可能已经完成了读取
+		if err := cs.mu.LockContext(ctx); err != nil {
+			var zero T
+			return zero, err
+		}
+		defer cs.mu.Unlock()
+		if cs.cacheValid() {
+			span.SetAttributes(attrCacheHit.Bool(true))
+			return cs.cached, nil
+		}
+		span.SetAttributes(attrCacheHit.Bool(false))
+		config, err := cs.loadAndCache(ctx)
+		if errors.Is(err, ErrCircuitOpen) && cs.hasStale {
+			// 断路器打开时返回最近一次成功读取的配置
+			return cs.stale, nil
+		}
+		return config, err
+	})
+	config, err = cs.afterLoad(config, err)
+	err = cs.wrapError("load", err)
+	endSpan(span, err)
+	cs.metrics.observe("load", start, err)
+	return config, err
+}
+
+// 读取成功后应用环境变量并调用 OnLoad 回调，回调在锁外执行，避免回调中再次读写配置时死锁
+func (cs *ConfigStore[T]) afterLoad(config T, err error) (T, error) {
+	if err != nil {
+		return config, err
+	}
+	if config, err = cs.overrideEnv(config); err != nil {
+		return config, err
+	}
+	for _, fn := range cs.onLoad {
+		fn(config)
+	}
+	return config, nil
+}
+
+// 还没有保存过配置时使用 defaultConfig，校验失败时保留读取到的配置
+func orDefault[T any](config T, err error, defaultConfig T) (T, error) {
+	if errors.Is(err, ErrNoConfig) {
+		return defaultConfig, nil
+	}
+	// 校验失败时返回读取到的配置，便于调用方知道保存的配置不合法
+	if errors.Is(err, ErrInvalidConfig) {
+		return config, err
+	}
 	if err != nil {
 		return defaultConfig, err
 	}
+	return config, nil
+}
+
+// 读取配置，同时返回保存时间，调用方需要持有锁
+func (cs *ConfigStore[T]) loadConfig(ctx context.Context) (T, time.Time, error) {
+	var config T
+
+	if err := ctx.Err(); err != nil {
+		return config, time.Time{}, context.Cause(ctx)
+	}
+	if cs.closed {
+		return config, time.Time{}, ErrStoreClosed
+	}
+	if len(cs.overlays) > 0 {
+		return cs.loadOverlays(ctx)
+	}
+
+	fileData, err := cs.readBackend(ctx)
+	if err != nil {
+		return config, time.Time{}, err
+	}
+	if len(fileData) == 0 {
+		return config, time.Time{}, ErrNoConfig
+	}
 
-	// 提取 IV 和加密数据
-	if len(fileData) < aes.BlockSize {
-		return defaultConfig, errors.New("invalid encrypted data")
+	// 解密文件内容，文件损坏时尝试从备份恢复
+	config, savedAt, err := cs.decryptConfig(ctx, fileData)
+	if err != nil && cs.recoveryMode && isCorruption(err) {
+		return cs.recoverFromBackup(ctx, err)
 	}
-	iv := fileData[:aes.BlockSize]
-	ciphertext := fileData[aes.BlockSize:]
+	return config, savedAt, err
+}
 
-	// 解密文件内容
-	decryptedData, err := decryptAES(ciphertext, []byte(cs.key), iv)
+// 从存储后端读取文件内容，记录修改时间并校验校验文件，文件为空时读取归档，调用方需要持有锁
+func (cs *ConfigStore[T]) readBackend(ctx context.Context) ([]byte, error) {
+	unlock, err := cs.lockFile(false)
 	if err != nil {
-		return defaultConfig, err
+		return nil, err
 	}
+	defer unlock()
+	var fileData []byte
+	err = cs.backendIO(ctx, func() (err error) {
+		fileData, err = cs.backend.Read()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	cs.recordModTime()
+	if len(fileData) > 0 {
+		return fileData, cs.verifyChecksum(fileData)
+	}
+	return cs.readArchive()
+}
 
-	// 将解密后的数据解析为配置对象
+// 解密、迁移并反序列化文件内容，同时返回头部记录的保存时间，超过 WithMaxAge 设置的有效期时返回 ErrConfigExpired
+func (cs *ConfigStore[T]) decode(secret []byte, fileData []byte) (T, time.Time, error) {
+	config, header, err := cs.decodeFile(secret, fileData)
+	savedAt := header.savedTime()
+	if err != nil {
+		return config, savedAt, err
+	}
+	if err := cs.checkExpired(savedAt); err != nil {
+		var zero T
+		return zero, savedAt, err
+	}
+	return config, savedAt, nil
+}
+
+// 解密、迁移并反序列化文件内容，不检查有效期
+func (cs *ConfigStore[T]) decodeFile(secret []byte, fileData []byte) (T, fileHeader, error) {
 	var config T
-	err = json.Unmarshal(decryptedData, &config)
+	decryptedData, header, err := cs.open(secret, fileData)
 	if err != nil {
-		return defaultConfig, err
+		return config, fileHeader{}, err
 	}
 
-	return config, nil
+	// 旧版本的数据先依次经过迁移
+	decryptedData, err = cs.migrate(decryptedData, int(header.dataVersion))
+	if err != nil {
+		return config, header, err
+	}
+
+	// 将解密后的数据解析为配置对象
+	err = cs.codec.Unmarshal(decryptedData, &config)
+	if err != nil {
+		var zero T
+		return zero, header, fmt.Errorf("%w: %w", ErrCorruptData, err)
+	}
+	return config, header, nil
 }
 
 func (cs *ConfigStore[T]) SaveConfig(config T) error {
+	ctx, cancel := cs.defaultContext()
+	defer cancel()
+	return cs.SaveConfigContext(ctx, config)
+}
+
+// SaveConfigContext 与 SaveConfig 相同，ctx 被取消或超时时立即返回 ctx.Err()。
+// 已经开始的写入会在后台完成，写入完成前其他读写操作仍然会等待。
+// 配置文件在上一次读取之后被其他进程修改时返回 ErrExternalModification，不会覆盖该修改，
+// 需要重新读取后再保存，或者使用 ForceWrite。
+func (cs *ConfigStore[T]) SaveConfigContext(ctx context.Context, config T) error {
+	return cs.save(ctx, config, false)
+}
+
+// 保存配置并调用回调，force 为 true 时不检查配置文件是否被其他进程修改
+func (cs *ConfigStore[T]) save(ctx context.Context, config T, force bool) error {
+	start := time.Now()
+	ctx, span := cs.startSpan(ctx, "configstore.Save")
+	_, err := runContext(ctx, func() (struct{}, error) {
+		if err := cs.mu.LockContext(ctx); err != nil {
+			return struct{}{}, err
+		}
+		defer cs.mu.Unlock()
+		return struct{}{}, cs.saveConfig(ctx, config, force)
+	})
+	err = cs.wrapError("save", err)
+	endSpan(span, err)
+	cs.metrics.observe("save", start, err)
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range cs.onSave {
+		fn(config)
+	}
+	return nil
+}
+
+// UpdateConfig 在持有写锁的情况下读取当前配置、调用 fn 并保存 fn 返回的配置，
+// 避免并发的“读取-修改-保存”相互覆盖。还没有保存过配置时 fn 收到 T 的零值，
+// fn 返回错误时不写入并返回该错误。fn 在锁内执行，不能在 fn 中调用 ConfigStore 的其他方法。
+func (cs *ConfigStore[T]) UpdateConfig(fn func(current T) (T, error)) error {
+	ctx, cancel := cs.defaultContext()
+	defer cancel()
+	if err := cs.mu.LockContext(ctx); err != nil {
+		return cs.wrapError("update", err)
+	}
+	config, _, err := cs.loadConfig(context.Background())
+	if errors.Is(err, ErrNoConfig) {
+		var zero T
+		config, err = zero, nil
+	}
+	if err == nil {
+		config, err = fn(config)
+	}
+	if err == nil {
+		err = cs.saveConfig(context.Background(), config, false)
+	}
+	cs.mu.Unlock()
+	if err != nil {
+		return cs.wrapError("update", err)
+	}
+
+	for _, fn := range cs.onSave {
+		fn(config)
+	}
+	return nil
+}
+
+// 保存配置，force 为 false 时检查配置文件是否被其他进程修改，调用方需要持有锁
+func (cs *ConfigStore[T]) saveConfig(ctx context.Context, config T, force bool) error {
+	// 先完整地生成文件内容，再一次性写入存储后端
+	var buf bytes.Buffer
+	if err := cs.encryptConfig(ctx, config, &buf); err != nil {
+		return err
+	}
+	encryptedData := buf.Bytes()
+
+	// 写入之前再检查一次 ctx，已取消时不再修改文件
+	if err := ctx.Err(); err != nil {
+		return context.Cause(ctx)
+	}
+
+	unlock, err := cs.lockFile(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !force {
+		if err := cs.checkModTime(); err != nil {
+			return err
+		}
+	}
+
+	// 写入前备份当前文件
+	if fb, ok := cs.fileBackend(); ok && cs.maxBackups > 0 {
+		if err := rotateBackups(fb.filename, cs.maxBackups); err != nil {
+			return err
+		}
+	}
+	// 超过大小限制时归档当前文件
+	if fb, ok := cs.fileBackend(); ok && cs.maxSize > 0 && int64(len(encryptedData)) > cs.maxSize {
+		if err := archiveFile(fb.filename); err != nil {
+			return err
+		}
+	}
+
+	// 将加密数据写入存储后端，下一次读取时重新加载缓存
+	cs.invalidateCache()
+	if err := cs.backendIO(ctx, func() error { return cs.backend.Write(encryptedData) }); err != nil {
+		return err
+	}
+	cs.recordModTime()
+	if err := cs.writeChecksum(encryptedData); err != nil {
+		return err
+	}
+	return cs.autoSnapshot(encryptedData)
+}
+
+// 在新的 goroutine 中执行 fn，ctx 被取消时不再等待 fn 返回，直接返回 ctx 被取消的原因 context.Cause(ctx)
+func runContext[R any](ctx context.Context, fn func() (R, error)) (R, error) {
+	var zero R
+	if err := ctx.Err(); err != nil {
+		return zero, context.Cause(ctx)
+	}
+	// 不会被取消的 ctx（如 context.Background()）不需要额外的 goroutine
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	type result struct {
+		value R
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return zero, context.Cause(ctx)
+	case r := <-done:
+		return r.value, r.err
+	}
+}
+
+// 获取当前的 key（或密码），设置了 KeyProvider 时从 KeyProvider 获取
+func (cs *ConfigStore[T]) secret(ctx context.Context) ([]byte, error) {
+	if cs.keyProvider == nil {
+		return cs.key, nil
+	}
+	key, err := cs.keyProvider.GetKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cs.kdf == KDFNone {
+		if err := cs.cipherMode.checkKeyLen(len(key)); err != nil {
+			return nil, err
+		}
+	} else if len(key) == 0 {
+		return nil, errEmptyPassword
+	}
+	return key, nil
+}
+
+// 使用给定的 key（或密码）加密配置数据，生成完整的文件内容：
+// [文件头部][派生参数][HMAC 标签][IV/nonce + 密文]，派生参数和 HMAC 标签只在启用时存在。
+// dataVersion 为明文对应的数据版本，0 表示不记录；savedAt 为保存时间，零值表示不记录。
+func (cs *ConfigStore[T]) seal(secret []byte, plaintext []byte, dataVersion int, savedAt time.Time) ([]byte, error) {
+	header := fileHeader{version: formatVersion, cipherMode: cs.cipherMode, format: cs.format}
+	if dataVersion > 0 {
+		header.flags |= flagDataVersion
+		header.dataVersion = uint32(dataVersion)
+	}
+	if !savedAt.IsZero() {
+		header.flags |= flagSavedAt
+		header.savedAt = savedAt.UnixNano()
+	}
+	if cs.kdf != KDFNone {
+		header.flags |= flagKDF
+	}
+	if cs.integrity {
+		header.flags |= flagIntegrity
+	}
+	header.flags |= cs.compression.flag()
+	prefix := header.marshal()
+
+	key := secret
+	if header.has(flagKDF) {
+		// 每次保存都使用新的盐派生 key
+		params, err := newKDFParams(&cs.storeConfig, cs.random)
+		if err != nil {
+			return nil, err
+		}
+		if key, err = params.deriveKey(secret); err != nil {
+			return nil, err
+		}
+		prefix = append(prefix, params.marshal()...)
+	}
+
+	// 先单独加密敏感字段，再压缩、加密整个文件
+	if cs.fields != nil {
+		var err error
+		if plaintext, err = cs.fields.encrypt(key, plaintext, cs.random); err != nil {
+			return nil, err
+		}
+	}
+	plaintext, err := cs.compression.compress(plaintext, cs.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	// IV/nonce 放在密文前面
+	encryptedData, err := sealData(header.cipherMode, plaintext, key, cs.random)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.has(flagIntegrity) {
+		tag, err := computeIntegrityTag(key, prefix, encryptedData)
+		if err != nil {
+			return nil, err
+		}
+		prefix = append(prefix, tag...)
+	}
+	return append(prefix, encryptedData...), nil
+}
+
+// 使用给定的 key（或密码）从文件内容中解密出配置数据，同时返回文件头部
+func (cs *ConfigStore[T]) open(secret []byte, fileData []byte) ([]byte, fileHeader, error) {
+	header, rest, err := parseFileHeader(fileData)
+	if err != nil {
+		return nil, fileHeader{}, err
+	}
+	if header.version == 0 {
+		// 没有头部的旧文件，格式由当前配置决定
+		header = cs.legacyHeader()
+	} else if err := cs.checkHeader(header); err != nil {
+		return nil, fileHeader{}, err
+	}
+
+	key := secret
+	if header.has(flagKDF) {
+		params, remaining, err := parseKDFParams(rest)
+		if err != nil {
+			return nil, fileHeader{}, err
+		}
+		if params.kdf != cs.kdf {
+			return nil, fileHeader{}, fmt.Errorf("%w: kdf mismatch: file uses %v, store is configured with %v", ErrStoreMismatch, params.kdf, cs.kdf)
+		}
+		if key, err = params.deriveKey(secret); err != nil {
+			return nil, fileHeader{}, err
+		}
+		rest = remaining
+	}
+
+	// 解密之前先校验 HMAC，标签之前的内容都受保护
+	if header.has(flagIntegrity) {
+		if len(rest) < integrityTagSize {
+			return nil, fileHeader{}, ErrIntegrityFailure
+		}
+		prefix := fileData[:len(fileData)-len(rest)]
+		tag := rest[:integrityTagSize]
+		rest = rest[integrityTagSize:]
+		if err := verifyIntegrityTag(key, prefix, tag, rest); err != nil {
+			return nil, fileHeader{}, err
+		}
+	}
+
+	plaintext, err := openData(header.cipherMode, rest, key)
+	if err != nil {
+		return nil, fileHeader{}, err
+	}
+	// 压缩方式以文件头部为准
+	plaintext, err = compressionFromFlags(header.flags).decompress(plaintext)
+	if err != nil {
+		return nil, fileHeader{}, err
+	}
+	if cs.fields != nil {
+		if plaintext, err = cs.fields.decrypt(key, plaintext); err != nil {
+			return nil, fileHeader{}, err
+		}
+	}
+	return plaintext, header, nil
+}
+
+// 版本 0 的文件没有头部，按照当前配置推断格式
+func (cs *ConfigStore[T]) legacyHeader() fileHeader {
+	header := fileHeader{cipherMode: cs.cipherMode, format: cs.format}
+	if cs.kdf != KDFNone {
+		header.flags |= flagKDF
+	}
+	if cs.integrity {
+		header.flags |= flagIntegrity
+	}
+	return header
+}
+
+// 检查文件头部与当前配置是否兼容
+func (cs *ConfigStore[T]) checkHeader(header fileHeader) error {
+	if header.has(flagKDF) && cs.kdf == KDFNone {
+		return fmt.Errorf("%w: file was written with a password derived key, use NewConfigStoreFromPassword", ErrStoreMismatch)
+	}
+	if !header.has(flagKDF) && cs.kdf != KDFNone {
+		return fmt.Errorf("%w: file was written with a raw key, use NewConfigStore", ErrStoreMismatch)
+	}
+	if header.format != cs.format {
+		return fmt.Errorf("%w: format mismatch: file uses %v, store is configured with %v", ErrStoreMismatch, header.format, cs.format)
+	}
+	// 加密模式以当前配置为准，防止伪造头部（例如 CipherModeNone）绕过解密和认证
+	if header.cipherMode != cs.cipherMode {
+		return fmt.Errorf("%w: cipher mode mismatch: file uses %v, store is configured with %v", ErrStoreMismatch, header.cipherMode, cs.cipherMode)
+	}
+	// 启用了完整性校验时不接受没有标签的文件，防止标签被剥离
+	if cs.integrity && !header.has(flagIntegrity) {
+		return ErrIntegrityFailure
+	}
+	return nil
+}
+
+// RotateKey 使用新的 key 重新加密已保存的配置，并更新内存中的 key。
+// 对于密码创建的 ConfigStore，newKey 为新的密码。写入失败时原文件保持不变。
+// 设置了 WithKeyFile 时在配置文件写入成功后更新 key 文件，key 文件写入失败时恢复原来的配置文件。
+func (cs *ConfigStore[T]) RotateKey(newKey string) (err error) {
+	defer func() { err = cs.wrapError("rotate", err) }()
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
-	// 将配置转换为字节切片
-	configData, err := json.Marshal(config)
+	if cs.closed {
+		return ErrStoreClosed
+	}
+	if cs.keyProvider != nil {
+		return fmt.Errorf("%w: cannot rotate a key managed by a KeyProvider", ErrUnsupported)
+	}
+	if cs.kdf == KDFNone {
+		if err := cs.cipherMode.checkKeyLen(len(newKey)); err != nil {
+			return err
+		}
+	} else if newKey == "" {
+		return errEmptyPassword
+	}
+
+	unlock, err := cs.lockFile(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	fileData, err := cs.backend.Read()
 	if err != nil {
 		return err
 	}
+	// 还没有保存过配置，只需要更新 key
+	if len(fileData) == 0 {
+		if err := cs.writeKeyFile(newKey); err != nil {
+			return err
+		}
+		cs.setKey([]byte(newKey))
+		return nil
+	}
 
-	// 加密配置数据
-	iv := make([]byte, aes.BlockSize)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+	// 使用旧 key 解密
+	plaintext, header, err := cs.open(cs.key, fileData)
+	if err != nil {
+		return err
+	}
+
+	// 使用新 key 和新的 IV 重新加密，数据没有经过迁移，保留原来的数据版本和保存时间
+	encryptedData, err := cs.seal([]byte(newKey), plaintext, int(header.dataVersion), header.savedTime())
+	if err != nil {
+		return err
+	}
+
+	// 备份同样需要使用新 key 重新加密，否则轮换后无法恢复
+	backups, err := cs.reencryptBackups(cs.key, []byte(newKey))
+	if err != nil {
+		return err
+	}
+
+	// FileBackend 先写入临时文件再替换，保证失败时旧文件仍然可用
+	if err := cs.backend.Write(encryptedData); err != nil {
+		return err
+	}
+	// key 文件写入失败时恢复原来的配置文件，保证 key 文件与配置文件一致
+	defer cs.recordModTime()
+	if err := cs.writeKeyFile(newKey); err != nil {
+		if restoreErr := cs.backend.Write(fileData); restoreErr != nil {
+			return errors.Join(err, restoreErr)
+		}
+		return err
+	}
+	if err := cs.writeChecksum(encryptedData); err != nil {
 		return err
 	}
-	encryptedData, err := encryptAES(configData, []byte(cs.key), iv)
+	cs.setKey([]byte(newKey))
+
+	for name, data := range backups {
+		if err := writeFile(name, data, cs.fileMode, cs.sync); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteConfig 删除保存的配置以及所有备份和归档文件，文件不存在时返回 nil。
+// 删除之后 LoadConfigOrDefault 返回默认配置，与新创建的 ConfigStore 一致。
+func (cs *ConfigStore[T]) DeleteConfig() (err error) {
+	defer func() { err = cs.wrapError("delete", err) }()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.closed {
+		return ErrStoreClosed
+	}
+	deleter, ok := cs.backend.(Deleter)
+	if !ok {
+		return fmt.Errorf("%w: backend does not support delete", ErrUnsupported)
+	}
+	unlock, err := cs.lockFile(true)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	// 将 IV 和加密数据写入文件
-	encryptedData = append(iv, encryptedData...)
-	return writeFile(cs.filename, encryptedData)
+	cs.invalidateCache()
+	if err := deleter.Delete(); err != nil {
+		return err
+	}
+	cs.recordModTime()
+	if fb, ok := cs.fileBackend(); ok {
+		for _, name := range []string{archiveName(fb.filename), checksumName(fb.filename)} {
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return removeBackups(fb.filename)
+	}
+	return nil
 }
 
-func createFile(filename string) error {
+// Reset 将 defaultConfig 作为当前配置保存，与 SaveConfig(defaultConfig) 相同，
+// 通常在 DeleteConfig 之后或首次初始化时使用，之后的读取不再返回 ErrNoConfig。
+func (cs *ConfigStore[T]) Reset(defaultConfig T) error {
+	return cs.SaveConfig(defaultConfig)
+}
+
+// ResetToZero 将 T 的零值作为当前配置保存
+func (cs *ConfigStore[T]) ResetToZero() error {
+	var zero T
+	return cs.Reset(zero)
+}
+
+var errEmptyPassword = fmt.Errorf("%w: password must not be empty", ErrInvalidOption)
+
+// Close 将内存中的 key 清零，之后所有读写操作都返回 ErrStoreClosed。重复调用 Close 返回 nil。
+// KeyProvider 返回的 key 由 KeyProvider 自己管理，不会被清零。
+func (cs *ConfigStore[T]) Close() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.setKey(nil)
+	cs.invalidateCache()
+	if cs.ephemeral != nil {
+		cs.ephemeral.wipe()
+	}
+	cs.closed = true
+	return nil
+}
+
+// String 返回用于日志的描述，例如 ConfigStore{file: app.data, keyLen: 32, format: JSON, cipher: AES-CBC}，
+// 不包含任何 key 的内容
+func (cs *ConfigStore[T]) String() string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	var b strings.Builder
+	b.WriteString("ConfigStore{")
+	if fb, ok := cs.fileBackend(); ok {
+		fmt.Fprintf(&b, "file: %s", fb.filename)
+	} else {
+		fmt.Fprintf(&b, "backend: %T", cs.backend)
+	}
+	if cs.keyProvider != nil {
+		fmt.Fprintf(&b, ", keyProvider: %T", cs.keyProvider)
+	} else {
+		fmt.Fprintf(&b, ", keyLen: %d", len(cs.key))
+	}
+	if cs.kdf != KDFNone {
+		fmt.Fprintf(&b, ", kdf: %s", cs.kdf)
+	}
+	fmt.Fprintf(&b, ", format: %s, cipher: %s}", cs.format, cs.cipherMode)
+	return b.String()
+}
+
+// GoString 与 String 相同，保证 %#v 也不会输出 key
+func (cs *ConfigStore[T]) GoString() string {
+	return cs.String()
+}
+
+// 替换 key，旧的 key 先清零，调用方需要持有写锁
+func (cs *ConfigStore[T]) setKey(key []byte) {
+	clear(cs.key)
+	cs.key = key
+}
+
+func createFile(filename string, mode, dirMode os.FileMode) error {
+	// 所在的目录不存在时先创建目录
+	if err := os.MkdirAll(filepath.Dir(filename), dirMode); err != nil {
+		return err
+	}
 	// 创建一个新的文件
-	_, err := os.Create(filename)
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, mode)
 	if err != nil {
 		return err
 	}
+	return file.Close()
+}
+
+// 已存在的文件权限比 mode 宽时收紧为 mode
+func restrictFileMode(filename string, mode os.FileMode) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&^mode.Perm() != 0 {
+		return os.Chmod(filename, mode.Perm())
+	}
 	return nil
 }
 
@@ -133,60 +1019,36 @@ func readFile(s string) ([]byte, error) {
 	return fileData, nil
 }
 
-func writeFile(s string, encryptedData []byte) error {
-	// 打开文件
-	file, err := os.OpenFile(s, os.O_WRONLY|os.O_TRUNC, 0644)
+// 先将数据写入同目录下的临时文件，再通过重命名原子地替换目标文件，
+// 写入过程中进程崩溃时原文件保持不变。
+// sync 为 true 时在重命名前刷新临时文件、重命名后刷新所在目录，保证断电后新内容和新的目录项都已落盘，
+// 代价是每次写入都需要等待磁盘完成两次 fsync。
+func writeFile(s string, encryptedData []byte, mode os.FileMode, sync bool) error {
+	tmpName := s + ".tmp"
+	file, err := os.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	// 将加密数据写入文件
+	// 将加密数据写入临时文件
 	_, err = file.Write(encryptedData)
+	if err == nil && sync {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
+		os.Remove(tmpName)
 		return err
 	}
-	return nil
-}
-
-// 填充数据以满足 AES 块大小
-func pkcs7Padding(data []byte, blockSize int) []byte {
-	padding := blockSize - len(data)%blockSize
-	padtext := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(data, padtext...)
-}
-
-// 去除填充数据
-func pkcs7UnPadding(data []byte) []byte {
-	length := len(data)
-	unpadding := int(data[length-1])
-	return data[:(length - unpadding)]
-}
 
-// 加密数据
-func encryptAES(data []byte, key []byte, iv []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
+	if err := renameFile(tmpName, s); err != nil {
+		os.Remove(tmpName)
+		return err
 	}
-	blockSize := block.BlockSize()
-	data = pkcs7Padding(data, blockSize)
-	ciphertext := make([]byte, len(data))
-	mode := cipher.NewCBCEncrypter(block, iv)
-	mode.CryptBlocks(ciphertext, data)
-	return ciphertext, nil
-}
-
-// 解密数据
-func decryptAES(data []byte, key []byte, iv []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
+	if sync {
+		return syncDir(filepath.Dir(s))
 	}
-	// blockSize := block.BlockSize()
-	mode := cipher.NewCBCDecrypter(block, iv)
-	plaintext := make([]byte, len(data))
-	mode.CryptBlocks(plaintext, data)
-	plaintext = pkcs7UnPadding(plaintext)
-	return plaintext, nil
+	return nil
 }
@@ -1,7 +1,19 @@
 package configstore
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type myConfig struct {
@@ -20,7 +32,7 @@ func TestNewConfigStore(t *testing.T) {
 	if cs.filename != filename {
 		t.Errorf("Expected filename to be %s, but got: %s", filename, cs.filename)
 	}
-	if cs.key != key {
+	if string(cs.key) != key {
 		t.Errorf("Expected key to be %s, but got: %s", key, cs.key)
 	}
 }
@@ -49,3 +61,966 @@ func TestSaveConfig(t *testing.T) {
 		t.Errorf("Expected password to be %s, but got: %s", config.Password, loadConfig.Password)
 	}
 }
+
+func TestRotateKey(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rotate.data")
+	oldKey := "0123456789abcdef"
+	newKey := "fedcba9876543210fedcba9876543210"
+	cs, err := NewConfigStore[myConfig](filename, oldKey)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := cs.RotateKey(newKey); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if string(cs.key) != newKey {
+		t.Errorf("Expected key to be updated to %s, but got: %s", newKey, cs.key)
+	}
+
+	// 轮换后当前实例和使用新 key 的实例都可以读取配置
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+	cs2, err := NewConfigStore[myConfig](filename, newKey)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err = cs2.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestRotateKeyWriteFailure(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rotate.data")
+	oldKey := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, oldKey)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 用目录占住临时文件的位置，使写入失败
+	if err := os.Mkdir(filename+".tmp", 0755); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.RotateKey("fedcba9876543210"); err == nil {
+		t.Fatalf("Expected an error, but got nil")
+	}
+	if string(cs.key) != oldKey {
+		t.Errorf("Expected key to stay %s, but got: %s", oldKey, cs.key)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestRotateKeyInvalidLength(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rotate.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.RotateKey("short"); !errors.Is(err, ErrInvalidKeyLength) {
+		t.Errorf("Expected ErrInvalidKeyLength, but got: %v", err)
+	}
+}
+
+func TestAtomicWriteInterrupted(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "atomic.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 模拟进程在写入临时文件的过程中被杀死：只留下一半数据的临时文件
+	data, _ := os.ReadFile(filename)
+	if err := os.WriteFile(filename+".tmp", data[:len(data)/2], 0644); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+
+	// 下一次保存会覆盖残留的临时文件
+	config.Password = "newpass"
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := os.Stat(filename + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected temp file to be removed, but got: %v", err)
+	}
+	loadConfig, err = cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestAtomicWriteFailure(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "atomic.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 写入失败时原文件保持不变
+	if err := os.Mkdir(filename+".tmp", 0755); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "other"}); err == nil {
+		t.Fatalf("Expected an error, but got nil")
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestLoadConfigNoConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "new.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 新创建的空文件
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+	defaultConfig := myConfig{Username: "default"}
+	loadConfig, err := cs.LoadConfigOrDefault(defaultConfig)
+	if err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v", loadConfig)
+	}
+
+	// 文件被删除
+	if err := os.Remove(filename); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+}
+
+func TestLoadConfigTruncated(t *testing.T) {
+	// 被截断的文件属于数据损坏，而不是“从未写入”
+	filename := filepath.Join(t.TempDir(), "truncated.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := os.WriteFile(filename, []byte("short"), 0644); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	_, err = cs.LoadConfig()
+	if !errors.Is(err, ErrCorruptData) {
+		t.Errorf("Expected ErrCorruptData, but got: %v", err)
+	}
+	defaultConfig := myConfig{Username: "default"}
+	loadConfig, err := cs.LoadConfigOrDefault(defaultConfig)
+	if err == nil {
+		t.Errorf("Expected an error, but got nil")
+	}
+	if loadConfig != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v", loadConfig)
+	}
+}
+
+func TestDeleteConfig(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "delete.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithBackup(2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2", "v3"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	// 另一个不相关的文件不应被删除
+	other := filepath.Join(dir, "delete.data.bak.notes")
+	if err := os.WriteFile(other, []byte("keep"), 0644); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := cs.DeleteConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{filename, backupName(filename, 1), backupName(filename, 2)} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, but got: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Expected unrelated file to be kept, but got: %v", err)
+	}
+
+	// 删除后返回默认配置
+	defaultConfig := myConfig{Username: "default"}
+	loadConfig, err := cs.LoadConfigOrDefault(defaultConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v", loadConfig)
+	}
+
+	// 重复删除不会报错，删除后仍可以重新保存
+	if err := cs.DeleteConfig(); err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "v4"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+}
+
+func TestDeleteConfigMemoryBackend(t *testing.T) {
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.DeleteConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defaultConfig := myConfig{Username: "default"}
+	if err := cs.Reset(defaultConfig); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 重置后读取到的是保存的默认配置，而不是 ErrNoConfig
+	config, err := cs.LoadConfig()
+	if err != nil || config != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v, %v", config, err)
+	}
+
+	if err := cs.ResetToZero(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err = cs.LoadConfig()
+	if err != nil || config != (myConfig{}) {
+		t.Errorf("Expected zero config, but got: %+v, %v", config, err)
+	}
+}
+
+func TestStoreError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "error.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	other, err := NewConfigStore[myConfig](filename, "fedcba9876543210", WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	_, err = other.LoadConfig()
+	var se *StoreError
+	if !errors.As(err, &se) {
+		t.Fatalf("Expected *StoreError, but got: %T %v", err, err)
+	}
+	if se.Op != "load" || se.File != filename {
+		t.Errorf("Expected op load and file %s, but got: %s %s", filename, se.Op, se.File)
+	}
+	// 通过 Unwrap 仍然可以与原始错误比较
+	if !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed, but got: %v", err)
+	}
+	if want := "configstore: load " + filename + ": "; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Expected message to start with %q, but got: %s", want, err.Error())
+	}
+
+	// 不使用 FileBackend 时 File 为空，构造函数的错误同样会被包装
+	_, err = NewConfigStore[myConfig]("", "short", WithBackend(NewMemoryBackend()))
+	if !errors.As(err, &se) || se.Op != "open" || !errors.Is(err, ErrInvalidKeyLength) {
+		t.Errorf("Expected open StoreError with ErrInvalidKeyLength, but got: %v", err)
+	}
+	if want := "configstore: open: "; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Expected message to start with %q, but got: %s", want, err.Error())
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var saved, loaded []string
+	var cs *ConfigStore[myConfig]
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef",
+		WithBackend(NewMemoryBackend()),
+		WithOnSave(func(c myConfig) { saved = append(saved, "first:"+c.Username) }),
+		WithOnSave(func(c myConfig) {
+			saved = append(saved, "second:"+c.Username)
+			// 回调在锁外执行，这里读取配置不会死锁
+			if _, err := cs.LoadConfig(); err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+		}),
+		WithOnLoad(func(c myConfig) { loaded = append(loaded, c.Username) }),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 没有保存过配置时返回默认值，不调用 OnLoad
+	if _, err := cs.LoadConfigOrDefault(myConfig{Username: "default"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(loaded) != 0 {
+		t.Errorf("Expected no load hook calls, but got: %v", loaded)
+	}
+
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(saved) != 2 || saved[0] != "first:testuser" || saved[1] != "second:testuser" {
+		t.Errorf("Expected both save hooks in order, but got: %v", saved)
+	}
+	if _, err := cs.LoadConfigOrDefault(myConfig{}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 第一次来自 OnSave 回调中的 LoadConfig
+	if len(loaded) != 2 || loaded[1] != "testuser" {
+		t.Errorf("Expected load hook to receive saved config, but got: %v", loaded)
+	}
+}
+
+func TestHooksTypeMismatch(t *testing.T) {
+	_, err := NewConfigStore[myConfig]("", "0123456789abcdef",
+		WithBackend(NewMemoryBackend()),
+		WithOnSave(func(s string) {}),
+	)
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Error("Expected an error for a hook of the wrong type, but got nil")
+	}
+}
+
+// 使用 go test -race 运行时检查并发读写是否存在数据竞争
+func TestConcurrentLoadAndSave(t *testing.T) {
+	cs, err := NewConfigStore[myConfig](filepath.Join(t.TempDir(), "concurrent.data"), "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				config, err := cs.LoadConfigOrDefault(myConfig{})
+				if err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+					return
+				}
+				if config.Username != "testuser" {
+					t.Errorf("Expected username testuser, but got: %s", config.Username)
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 20; j++ {
+			if err := cs.SaveConfig(myConfig{Username: "testuser", Password: "testpassword"}); err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestUpdateConfig(t *testing.T) {
+	type counterConfig struct {
+		Count int `json:"count"`
+	}
+	cs, err := NewConfigStore[counterConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 两个 goroutine 并发递增，不应丢失任何一次更新
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				err := cs.UpdateConfig(func(c counterConfig) (counterConfig, error) {
+					c.Count++
+					return c, nil
+				})
+				if err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	config, err := cs.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Count != 100 {
+		t.Errorf("Expected count 100, but got: %d", config.Count)
+	}
+
+	// fn 返回错误时不写入
+	errAbort := errors.New("abort")
+	err = cs.UpdateConfig(func(c counterConfig) (counterConfig, error) {
+		return counterConfig{Count: -1}, errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Errorf("Expected errAbort, but got: %v", err)
+	}
+	if config, _ := cs.LoadConfig(); config.Count != 100 {
+		t.Errorf("Expected count to stay 100, but got: %d", config.Count)
+	}
+}
+
+// 在 release 关闭前阻塞所有读写的存储后端
+type blockingBackend struct {
+	MemoryBackend
+	release chan struct{}
+}
+
+func (b *blockingBackend) Read() ([]byte, error) {
+	<-b.release
+	return b.MemoryBackend.Read()
+}
+
+func (b *blockingBackend) Write(data []byte) error {
+	<-b.release
+	return b.MemoryBackend.Write(data)
+}
+
+func TestContextMethods(t *testing.T) {
+	backend := &blockingBackend{release: make(chan struct{})}
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(backend))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 后端阻塞时超时返回 ctx.Err()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := cs.SaveConfigContext(ctx, myConfig{Username: "testuser"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, but got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected save to abort promptly, but it took %v", elapsed)
+	}
+
+	// 已经取消的 ctx 直接返回，返回默认配置
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	defaultConfig := myConfig{Username: "default"}
+	config, err := cs.LoadConfigContext(canceled, defaultConfig)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got: %v", err)
+	}
+	if config != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v", config)
+	}
+
+	// 后端恢复后，之前开始的写入在后台完成
+	close(backend.release)
+	config, err = cs.LoadConfigContext(context.Background(), defaultConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Username != "testuser" {
+		t.Errorf("Expected username testuser, but got: %s", config.Username)
+	}
+}
+
+func TestNew(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "new.data")
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpassword"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 旧的构造函数仍然可以读取
+	old, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := old.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	if _, err := New[myConfig](WithKey("0123456789abcdef")); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption without a file, but got: %v", err)
+	}
+	filename := filepath.Join(t.TempDir(), "new.data")
+	if _, err := New[myConfig](WithFile(filename)); !errors.Is(err, ErrInvalidKeyLength) {
+		t.Errorf("Expected ErrInvalidKeyLength without a key, but got: %v", err)
+	}
+}
+
+func TestMustNewConfigStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "must.data")
+	if cs := MustNewConfigStore[myConfig](filename, "0123456789abcdef"); cs == nil {
+		t.Fatal("Expected a ConfigStore, but got nil")
+	}
+
+	// key 长度不合法时 panic，panic 信息包含文件名和原因
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok {
+			t.Fatalf("Expected panic with an error, but got: %v", r)
+		}
+		if !errors.Is(err, ErrInvalidKeyLength) {
+			t.Errorf("Expected ErrInvalidKeyLength, but got: %v", err)
+		}
+		if !strings.Contains(err.Error(), filename) {
+			t.Errorf("Expected panic message to contain %s, but got: %v", filename, err)
+		}
+	}()
+	MustNewConfigStore[myConfig](filename, "short")
+}
+
+func TestWithSync(t *testing.T) {
+	dir := t.TempDir()
+	for _, enabled := range []bool{true, false} {
+		filename := filepath.Join(dir, "sync-"+strconv.FormatBool(enabled)+".data")
+		cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithSync(enabled))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if fb, _ := cs.fileBackend(); fb.sync != enabled {
+			t.Errorf("Expected FileBackend sync to be %v, but got: %v", enabled, fb.sync)
+		}
+		config := myConfig{Username: "testuser"}
+		if err := cs.SaveConfig(config); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		loadConfig, err := cs.LoadConfig()
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if loadConfig != config {
+			t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+		}
+	}
+
+	// 默认刷新到磁盘
+	cs, err := New[myConfig](WithFile(filepath.Join(dir, "default.data")), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if fb, _ := cs.fileBackend(); !fb.sync {
+		t.Errorf("Expected sync to be enabled by default")
+	}
+	if !NewFileBackend("config.data").sync {
+		t.Errorf("Expected NewFileBackend to sync by default")
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on windows")
+	}
+	dir := t.TempDir()
+
+	// 默认只有所有者可以读写
+	filename := filepath.Join(dir, "mode.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected file mode 0600, but got: %v", info.Mode().Perm())
+	}
+
+	// 已存在的文件权限过宽时被收紧
+	existing := filepath.Join(dir, "existing.data")
+	if err := os.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := os.Chmod(existing, 0666); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	cs, err = NewConfigStore[myConfig](existing, "0123456789abcdef", WithFileMode(0640))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0640 {
+		t.Errorf("Expected file mode 0640, but got: %v", info.Mode().Perm())
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0640 {
+		t.Errorf("Expected file mode 0640 after save, but got: %v", info.Mode().Perm())
+	}
+}
+
+func TestDirMode(t *testing.T) {
+	dir := t.TempDir()
+
+	// 不存在的多级目录被自动创建
+	filename := filepath.Join(dir, "myapp", "config", "settings.data")
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	info, err := os.Stat(filepath.Dir(filename))
+	if err != nil || !info.IsDir() {
+		t.Fatalf("Expected directory to be created, but got: %v", err)
+	}
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if info.Mode().Perm() != DefaultDirMode {
+		t.Errorf("Expected dir mode %v, but got: %v", DefaultDirMode, info.Mode().Perm())
+	}
+
+	filename = filepath.Join(dir, "shared", "settings.data")
+	if _, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithDirMode(0750)); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if info, _ := os.Stat(filepath.Dir(filename)); info.Mode().Perm() != 0750 {
+		t.Errorf("Expected dir mode 0750, but got: %v", info.Mode().Perm())
+	}
+}
+
+func TestClose(t *testing.T) {
+	cs, err := NewConfigStore[myConfig](filepath.Join(t.TempDir(), "close.data"), "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	key := cs.key
+
+	if err := cs.Close(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// key 的内容被清零
+	for _, b := range key {
+		if b != 0 {
+			t.Fatalf("Expected key to be zeroed, but got: %q", key)
+		}
+	}
+	if err := cs.SaveConfig(myConfig{}); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Expected ErrStoreClosed, but got: %v", err)
+	}
+	if _, err := cs.LoadConfigOrDefault(myConfig{}); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Expected ErrStoreClosed, but got: %v", err)
+	}
+	if err := cs.RotateKey("fedcba9876543210"); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Expected ErrStoreClosed, but got: %v", err)
+	}
+	if err := cs.Close(); err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	key := "0123456789abcdef0123456789abcdef"
+	cs, err := New[myConfig](WithFile("app.data"), WithKey(key))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defer os.Remove("app.data")
+	expected := "ConfigStore{file: app.data, keyLen: 32, format: JSON, cipher: AES-CBC}"
+	if s := fmt.Sprint(cs); s != expected {
+		t.Errorf("Expected %s, but got: %s", expected, s)
+	}
+
+	password := "correct horse battery staple"
+	byPassword, err := Open[myConfig](filepath.Join(t.TempDir(), "password.data"), password)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 所有格式化动词都不会输出 key 或密码
+	for _, verb := range []string{"%v", "%+v", "%s", "%#v"} {
+		if s := fmt.Sprintf(verb, cs); strings.Contains(s, key) {
+			t.Errorf("Expected %s output not to contain the key, but got: %s", verb, s)
+		}
+		if s := fmt.Sprintf(verb, byPassword); strings.Contains(s, password) || !strings.Contains(s, "kdf: ") {
+			t.Errorf("Expected %s output not to contain the password, but got: %s", verb, s)
+		}
+	}
+}
+
+func TestNewEphemeralStore(t *testing.T) {
+	if _, err := NewEphemeralStore[myConfig]("short"); !errors.Is(err, ErrInvalidKeyLength) {
+		t.Errorf("Expected ErrInvalidKeyLength, but got: %v", err)
+	}
+	cs, err := NewEphemeralStore[myConfig]("0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded, err := cs.LoadConfig(); err != nil || loaded != config {
+		t.Errorf("Expected %+v, but got: %+v, %v", config, loaded, err)
+	}
+	// 内存中保存的是密文
+	data := cs.ephemeral.data
+	if strings.Contains(string(data), "testpass") {
+		t.Errorf("Expected in-memory data to be encrypted")
+	}
+
+	if err := cs.Close(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 内存中的密文被清零
+	for _, b := range data {
+		if b != 0 {
+			t.Fatalf("Expected in-memory data to be zeroed, but got: %q", data)
+		}
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Expected ErrStoreClosed, but got: %v", err)
+	}
+}
+
+// 基准测试使用的 key，分别对应 AES-128、AES-192 和 AES-256
+var benchmarkKeys = []string{
+	"0123456789abcdef",
+	"0123456789abcdef01234567",
+	"0123456789abcdef0123456789abcdef",
+}
+
+// 对每种 key 长度，分别在启用和不启用缓存时运行 fn
+func runBenchmarks(b *testing.B, fn func(b *testing.B, key string, opts []Option)) {
+	for _, key := range benchmarkKeys {
+		for _, cache := range []bool{false, true} {
+			var opts []Option
+			if cache {
+				opts = append(opts, WithCache())
+			}
+			b.Run("key="+strconv.Itoa(len(key))+"/cache="+strconv.FormatBool(cache), func(b *testing.B) {
+				fn(b, key, opts)
+			})
+		}
+	}
+}
+
+func newBenchmarkStore(b *testing.B, filename, key string, opts []Option) *ConfigStore[myConfig] {
+	b.Helper()
+	cs, err := New[myConfig](append([]Option{WithFile(filename), WithKey(key)}, opts...)...)
+	if err != nil {
+		b.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser", Password: "testpass"}); err != nil {
+		b.Fatalf("Expected no error, but got: %v", err)
+	}
+	return cs
+}
+
+// 每个 goroutine 使用的 ConfigStore 写入不同的临时文件，避免文件锁的竞争
+func newBenchmarkPool(b *testing.B, key string, opts []Option) func() *ConfigStore[myConfig] {
+	b.Helper()
+	dir := b.TempDir()
+	pool := make([]*ConfigStore[myConfig], runtime.GOMAXPROCS(0))
+	for i := range pool {
+		pool[i] = newBenchmarkStore(b, filepath.Join(dir, strconv.Itoa(i)+".data"), key, opts)
+	}
+	var next atomic.Int64
+	return func() *ConfigStore[myConfig] {
+		return pool[int(next.Add(1)-1)%len(pool)]
+	}
+}
+
+func BenchmarkSaveConfig(b *testing.B) {
+	runBenchmarks(b, func(b *testing.B, key string, opts []Option) {
+		cs := newBenchmarkStore(b, filepath.Join(b.TempDir(), "bench.data"), key, opts)
+		config := myConfig{Username: "testuser", Password: "testpass"}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := cs.SaveConfig(config); err != nil {
+				b.Fatalf("Expected no error, but got: %v", err)
+			}
+		}
+	})
+}
+
+func BenchmarkLoadConfig(b *testing.B) {
+	runBenchmarks(b, func(b *testing.B, key string, opts []Option) {
+		cs := newBenchmarkStore(b, filepath.Join(b.TempDir(), "bench.data"), key, opts)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := cs.LoadConfig(); err != nil {
+				b.Fatalf("Expected no error, but got: %v", err)
+			}
+		}
+	})
+}
+
+func BenchmarkSaveConfigParallel(b *testing.B) {
+	runBenchmarks(b, func(b *testing.B, key string, opts []Option) {
+		store := newBenchmarkPool(b, key, opts)
+		config := myConfig{Username: "testuser", Password: "testpass"}
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			cs := store()
+			for pb.Next() {
+				if err := cs.SaveConfig(config); err != nil {
+					b.Errorf("Expected no error, but got: %v", err)
+					return
+				}
+			}
+		})
+	})
+}
+
+func BenchmarkLoadConfigParallel(b *testing.B) {
+	runBenchmarks(b, func(b *testing.B, key string, opts []Option) {
+		store := newBenchmarkPool(b, key, opts)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			cs := store()
+			for pb.Next() {
+				if _, err := cs.LoadConfig(); err != nil {
+					b.Errorf("Expected no error, but got: %v", err)
+					return
+				}
+			}
+		})
+	})
+}
+
+// 被篡改的密文和 IV 只应使解密返回错误，不应 panic
+func FuzzDecryptAES(f *testing.F) {
+	key := []byte("0123456789abcdef")
+	for _, plaintext := range []string{"", "a", `{"username":"testuser","password":"testpass"}`} {
+		iv := make([]byte, 16)
+		if _, err := rand.Read(iv); err != nil {
+			f.Fatalf("Expected no error, but got: %v", err)
+		}
+		ciphertext, err := encryptAES([]byte(plaintext), key, iv)
+		if err != nil {
+			f.Fatalf("Expected no error, but got: %v", err)
+		}
+		f.Add(ciphertext, iv)
+	}
+	f.Add([]byte{}, make([]byte, 16))
+	f.Add(make([]byte, 16), make([]byte, 15))
+
+	f.Fuzz(func(t *testing.T, ciphertext, iv []byte) {
+		plaintext, err := decryptAES(ciphertext, key, iv)
+		if err == nil && len(plaintext) >= len(ciphertext) {
+			t.Errorf("Expected padding to be removed, but got %d bytes from %d", len(plaintext), len(ciphertext))
+		}
+	})
+}
+
+// 任意文件内容都不应使 LoadConfigOrDefault panic
+func FuzzLoadConfigOrDefault(f *testing.F) {
+	key := "0123456789abcdef"
+	cs, err := New[myConfig](WithBackend(NewMemoryBackend()), WithKey(key))
+	if err != nil {
+		f.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		f.Fatalf("Expected no error, but got: %v", err)
+	}
+	valid, err := cs.backend.Read()
+	if err != nil {
+		f.Fatalf("Expected no error, but got: %v", err)
+	}
+	f.Add(valid)
+	f.Add(valid[:len(valid)/2])
+	f.Add([]byte{})
+	f.Add([]byte(`{"username":"plaintext"}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		filename := filepath.Join(t.TempDir(), "fuzz.data")
+		if err := os.WriteFile(filename, data, 0600); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		cs, err := New[myConfig](WithFile(filename), WithKey(key))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		cs.LoadConfigOrDefault(myConfig{Username: "default"})
+	})
+}
@@ -0,0 +1,87 @@
+package configstore
+
+import (
+	"sync"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// ConsulBackend 将配置数据保存在 Consul KV 的一个 key 中
+type ConsulBackend struct {
+	client *api.Client
+	key    string
+	cas    bool
+
+	mu          sync.Mutex
+	modifyIndex uint64
+}
+
+// ConsulBackendOption 用于调整 ConsulBackend 的默认配置
+type ConsulBackendOption func(*ConsulBackend)
+
+// WithConsulCAS 使用 Consul 的 check-and-set 写入：每次读取后记录 key 的 ModifyIndex，
+// key 在此期间被其他客户端修改时写入失败并返回 ErrConflict。
+// 还没有读取过时，只有 key 不存在才能写入成功，因此通常先调用 LoadConfig，或者使用 UpdateConfig。
+func WithConsulCAS() ConsulBackendOption {
+	return func(b *ConsulBackend) {
+		b.cas = true
+	}
+}
+
+// NewConsulBackend 创建一个保存到 Consul KV 中 key 的 ConsulBackend
+func NewConsulBackend(client *api.Client, key string, opts ...ConsulBackendOption) *ConsulBackend {
+	b := &ConsulBackend{client: client, key: key}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *ConsulBackend) Read() ([]byte, error) {
+	pair, _, err := b.client.KV().Get(b.key, nil)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if pair == nil {
+		b.modifyIndex = 0
+		return nil, nil
+	}
+	b.modifyIndex = pair.ModifyIndex
+	return pair.Value, nil
+}
+
+func (b *ConsulBackend) Write(data []byte) error {
+	if !b.cas {
+		_, err := b.client.KV().Put(&api.KVPair{Key: b.key, Value: data}, nil)
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// 使用事务写入，可以同时得到写入后的 ModifyIndex；Index 为 0 表示 key 必须不存在
+	ok, resp, _, err := b.client.Txn().Txn(api.TxnOps{
+		&api.TxnOp{KV: &api.KVTxnOp{Verb: api.KVCAS, Key: b.key, Value: data, Index: b.modifyIndex}},
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrConflict
+	}
+	if len(resp.Results) > 0 && resp.Results[0].KV != nil {
+		b.modifyIndex = resp.Results[0].KV.ModifyIndex
+	}
+	return nil
+}
+
+func (b *ConsulBackend) Delete() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.client.KV().Delete(b.key, nil); err != nil {
+		return err
+	}
+	b.modifyIndex = 0
+	return nil
+}
@@ -0,0 +1,116 @@
+package configstore
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+)
+
+// 启动本地的 Consul 测试服务器，没有安装 consul 时跳过测试
+func newTestConsul(t *testing.T) *api.Client {
+	t.Helper()
+	if _, err := exec.LookPath("consul"); err != nil {
+		t.Skip("consul binary not found on $PATH")
+	}
+	server, err := testutil.NewTestServerConfigT(t, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	t.Cleanup(func() { server.Stop() })
+	server.WaitForLeader(t)
+	client, err := api.NewClient(&api.Config{Address: server.HTTPAddr})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	return client
+}
+
+func TestConsulBackend(t *testing.T) {
+	client := newTestConsul(t)
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewConsulBackend(client, "app/config")))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+
+	config := myConfig{Username: "testuser", Password: "testpassword"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected %+v, but got: %+v", config, loadConfig)
+	}
+
+	if err := cs.DeleteConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+}
+
+func TestConsulBackendCAS(t *testing.T) {
+	client := newTestConsul(t)
+	cs1, _ := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewConsulBackend(client, "app/config", WithConsulCAS())))
+	cs2, _ := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewConsulBackend(client, "app/config", WithConsulCAS())))
+	if err := cs1.SaveConfig(myConfig{Username: "v1"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 连续写入时使用上一次写入后的 ModifyIndex
+	if err := cs1.SaveConfig(myConfig{Username: "v1"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if _, err := cs1.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs2.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs2.SaveConfig(myConfig{Username: "v2"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// cs1 基于旧的 ModifyIndex 写入，不应覆盖 cs2 的修改
+	if err := cs1.SaveConfig(myConfig{Username: "v3"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, but got: %v", err)
+	}
+	if config, _ := cs1.LoadConfig(); config.Username != "v2" {
+		t.Errorf("Expected username v2, but got: %s", config.Username)
+	}
+}
+
+func TestConsulBackendServerError(t *testing.T) {
+	// 测试服务器无法模拟的错误，例如集群没有 leader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "No cluster leader", http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	client, err := api.NewClient(&api.Config{Address: server.URL})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	for _, backend := range []*ConsulBackend{NewConsulBackend(client, "app/config"), NewConsulBackend(client, "app/config", WithConsulCAS())} {
+		cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if _, err := cs.LoadConfig(); err == nil || errors.Is(err, ErrNoConfig) {
+			t.Errorf("Expected a server error from LoadConfig, but got: %v", err)
+		}
+		if err := cs.SaveConfig(myConfig{Username: "testuser"}); err == nil || errors.Is(err, ErrConflict) {
+			t.Errorf("Expected a server error from SaveConfig, but got: %v", err)
+		}
+	}
+}
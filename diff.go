@@ -0,0 +1,160 @@
+package configstore
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldChange 表示一个字段的变化，Field 为以 "." 连接的 JSON 字段名路径
+type FieldChange struct {
+	Field    string `json:"field"`
+	OldValue any    `json:"old_value"`
+	NewValue any    `json:"new_value"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Diff 比较两个配置，返回所有发生变化的字段。嵌套结构体会递归比较，
+// slice、map 以及实现了 json.Marshaler 或 encoding.TextMarshaler 的类型（如 time.Time）作为整体比较。
+// T 必须是结构体或指向结构体的指针。
+func Diff[T any](a, b T) ([]FieldChange, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: diff requires a struct config type, got %v", ErrUnsupported, t)
+	}
+	changes := []FieldChange{}
+	diffValue(&changes, "", reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem())
+	return changes, nil
+}
+
+// DiffWithStored 比较当前保存的配置和 candidate，还没有保存过配置时与 T 的零值比较。
+// 比较的是文件中保存的内容，不应用环境变量，也不调用 OnLoad 回调。
+func (cs *ConfigStore[T]) DiffWithStored(candidate T) ([]FieldChange, error) {
+	stored, err := cs.loadStored()
+	if err != nil {
+		return nil, err
+	}
+	return Diff(stored, candidate)
+}
+
+// 读取文件中保存的配置，不应用环境变量也不调用 OnLoad 回调，还没有保存过配置时返回 T 的零值
+func (cs *ConfigStore[T]) loadStored() (T, error) {
+	var zero T
+	ctx, cancel := cs.defaultContext()
+	defer cancel()
+	if err := cs.mu.RLockContext(ctx); err != nil {
+		return zero, cs.wrapError("load", err)
+	}
+	config, _, err := cs.loadConfig(ctx)
+	cs.mu.RUnlock()
+	if errors.Is(err, ErrNoConfig) {
+		return zero, nil
+	}
+	if err != nil {
+		return zero, cs.wrapError("load", err)
+	}
+	return config, nil
+}
+
+// ConfigEqual 使用 reflect.DeepEqual 比较两个配置，T 中包含 slice、map 等不能使用 == 比较的字段时同样适用。
+// 注意 time.Time 会同时比较时区和单调时钟读数，经过序列化的时间与原值可能不相等。
+func ConfigEqual[T any](a, b T) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// HasChanges 返回 candidate 与当前保存的配置是否不同，可以在保存前判断以避免不必要的写入。
+// 还没有保存过配置时与 T 的零值比较。与 DiffWithStored 相同，不应用环境变量，也不调用 OnLoad 回调。
+func (cs *ConfigStore[T]) HasChanges(candidate T) (bool, error) {
+	stored, err := cs.loadStored()
+	if err != nil {
+		return false, err
+	}
+	return !ConfigEqual(stored, candidate), nil
+}
+
+func diffValue(changes *[]FieldChange, path string, a, b reflect.Value) {
+	t := a.Type()
+	if isDiffLeaf(t) {
+		if !leafEqual(a, b) {
+			*changes = append(*changes, FieldChange{Field: path, OldValue: a.Interface(), NewValue: b.Interface()})
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*changes = append(*changes, FieldChange{Field: path, OldValue: a.Interface(), NewValue: b.Interface()})
+			}
+			return
+		}
+		diffValue(changes, path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if isPromotedStruct(field) {
+				diffValue(changes, path, a.Field(i), b.Field(i))
+				continue
+			}
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			diffValue(changes, name, a.Field(i), b.Field(i))
+		}
+	}
+}
+
+// 作为整体比较的类型：非结构体（指向结构体的指针除外）以及自定义了序列化方式的类型
+func isDiffLeaf(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return false
+	case reflect.Pointer:
+		return t.Elem().Kind() != reflect.Struct
+	default:
+		return true
+	}
+}
+
+func leafEqual(a, b reflect.Value) bool {
+	// time.Time 的 == 会比较时区和单调时钟，这里只比较时刻
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// 返回字段对应的 JSON 字段名，未导出或标记为 "-" 的字段返回 false
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
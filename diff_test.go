@@ -0,0 +1,180 @@
+package configstore
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type diffConfig struct {
+	Name     string    `json:"name"`
+	Tags     []string  `json:"tags"`
+	Updated  time.Time `json:"updated"`
+	Internal string    `json:"-"`
+	Server   struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	} `json:"server"`
+	TLS *struct {
+		Cert string `json:"cert"`
+	} `json:"tls"`
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	var a, b diffConfig
+	a.Name, b.Name = "app", "app"
+	a.Tags, b.Tags = []string{"a"}, []string{"a", "b"}
+	a.Updated, b.Updated = now, now.Add(time.Second)
+	a.Internal, b.Internal = "x", "y"
+	a.Server.Host, b.Server.Host = "localhost", "localhost"
+	a.Server.Port, b.Server.Port = 80, 8080
+
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []FieldChange{
+		{Field: "tags", OldValue: []string{"a"}, NewValue: []string{"a", "b"}},
+		{Field: "updated", OldValue: now, NewValue: now.Add(time.Second)},
+		{Field: "server.port", OldValue: 80, NewValue: 8080},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes to be %+v, but got: %+v", expected, changes)
+	}
+	if _, err := json.Marshal(changes); err != nil {
+		t.Errorf("Expected changes to be serializable, but got: %v", err)
+	}
+
+	// 相同的配置没有变化，time.Time 只比较时刻
+	b = a
+	b.Updated = a.Updated.UTC()
+	changes, err = Diff(a, b)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, but got: %+v", changes)
+	}
+
+	// 指针从 nil 变为非 nil
+	b.TLS = &struct {
+		Cert string `json:"cert"`
+	}{Cert: "cert.pem"}
+	changes, _ = Diff(a, b)
+	if len(changes) != 1 || changes[0].Field != "tls" {
+		t.Errorf("Expected a change on tls, but got: %+v", changes)
+	}
+}
+
+func TestDiffWithStored(t *testing.T) {
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser", Password: "testpassword"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	changes, err := cs.DiffWithStored(myConfig{Username: "testuser", Password: "newpassword"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []FieldChange{{Field: "password", OldValue: "testpassword", NewValue: "newpassword"}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes to be %+v, but got: %+v", expected, changes)
+	}
+}
+
+func TestDiffWithStoredIgnoresEnvOverride(t *testing.T) {
+	cs, err := New[envConfig](WithBackend(NewMemoryBackend()), WithKey("0123456789abcdef"), WithEnvOverride("APP"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	stored := envConfig{Name: "stored", Port: 80}
+	if err := cs.SaveConfig(stored); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 只通过环境变量设置的值不属于保存的配置
+	t.Setenv("APP_PORT", "8080")
+	changes, err := cs.DiffWithStored(stored)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Expected no changes, but got: %+v, %v", changes, err)
+	}
+	candidate := stored
+	candidate.Port = 8080
+	changes, err = cs.DiffWithStored(candidate)
+	expected := []FieldChange{{Field: "Port", OldValue: 80, NewValue: 8080}}
+	if err != nil || !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes to be %+v, but got: %+v, %v", expected, changes, err)
+	}
+}
+
+func TestConfigEqual(t *testing.T) {
+	a := diffConfig{Name: "app", Tags: []string{"a", "b"}}
+	b := diffConfig{Name: "app", Tags: []string{"a", "b"}}
+	if !ConfigEqual(a, b) {
+		t.Errorf("Expected configs to be equal")
+	}
+	b.Tags = append(b.Tags, "c")
+	if ConfigEqual(a, b) {
+		t.Errorf("Expected configs with different tags to be different")
+	}
+}
+
+func TestHasChanges(t *testing.T) {
+	cs, err := New[diffConfig](WithBackend(NewMemoryBackend()), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 还没有保存过配置时与零值比较
+	changed, err := cs.HasChanges(diffConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if changed {
+		t.Errorf("Expected zero config to have no changes")
+	}
+
+	config := diffConfig{Name: "app", Tags: []string{"a"}}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if changed, err = cs.HasChanges(config); err != nil || changed {
+		t.Errorf("Expected no changes, but got: %v, %v", changed, err)
+	}
+	config.Tags = []string{"b"}
+	if changed, err = cs.HasChanges(config); err != nil || !changed {
+		t.Errorf("Expected changes, but got: %v, %v", changed, err)
+	}
+}
+
+func TestDiffNonStruct(t *testing.T) {
+	if _, err := Diff(1, 2); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, but got: %v", err)
+	}
+}
+
+func TestHasChangesIgnoresEnvOverride(t *testing.T) {
+	cs, err := New[envConfig](WithBackend(NewMemoryBackend()), WithKey("0123456789abcdef"), WithEnvOverride("APP"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	stored := envConfig{Name: "stored", Port: 80}
+	if err := cs.SaveConfig(stored); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 文件没有变化时，设置了环境变量也不应报告变化
+	t.Setenv("APP_PORT", "8080")
+	if changed, err := cs.HasChanges(stored); err != nil || changed {
+		t.Errorf("Expected no changes, but got: %v, %v", changed, err)
+	}
+	candidate := stored
+	candidate.Port = 8080
+	if changed, err := cs.HasChanges(candidate); err != nil || !changed {
+		t.Errorf("Expected changes, but got: %v, %v", changed, err)
+	}
+}
@@ -0,0 +1,113 @@
+package configstore
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// EnvOption 用于配置 ExportEnv 的行为
+type EnvOption func(*envOptions)
+
+type envOptions struct {
+	includeSecrets bool
+}
+
+// WithIncludeSecrets 导出时包含标记了 `configstore:"secret"` 的字段，默认不导出这些字段
+func WithIncludeSecrets() EnvOption {
+	return func(o *envOptions) {
+		o.includeSecrets = true
+	}
+}
+
+// ExportEnv 将 config 转换为 dotenv 格式的 KEY=VALUE 行（按变量名排序，由 godotenv 生成），
+// 变量名与 WithEnvOverride 相同（不含前缀，优先使用 json 标签中的名字），嵌套结构体使用 OUTER_INNER。
+// 只导出 WithEnvOverride 支持的字段类型，其他类型的字段会被忽略。T 必须是结构体。
+func ExportEnv[T any](config T, opts ...EnvOption) (string, error) {
+	var o envOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Struct {
+		return "", fmt.Errorf("%w: env export requires a struct config type, got %v", ErrInvalidOption, v.Type())
+	}
+	env := make(map[string]string)
+	exportEnv(env, v, "", o)
+	if len(env) == 0 {
+		return "", nil
+	}
+	s, err := godotenv.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+	return s + "\n", nil
+}
+
+// ImportEnv 解析 dotenv 格式的内容并设置 T 中对应的字段，内容中没有的字段保持零值。T 必须是结构体。
+func ImportEnv[T any](s string) (T, error) {
+	var config T
+	v := reflect.ValueOf(&config).Elem()
+	if v.Kind() != reflect.Struct {
+		return config, fmt.Errorf("%w: env import requires a struct config type, got %v", ErrInvalidOption, v.Type())
+	}
+	env, err := godotenv.Unmarshal(s)
+	if err != nil {
+		return config, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	err = applyEnv(v, "", func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	return config, err
+}
+
+func exportEnv(env map[string]string, v reflect.Value, prefix string, o envOptions) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !o.includeSecrets && hasTagOption(field, "secret") {
+			continue
+		}
+		if isPromotedStruct(field) {
+			exportEnv(env, v.Field(i), prefix, o)
+			continue
+		}
+		jsonName, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		name := envName(prefix, jsonName)
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			exportEnv(env, fv, name, o)
+			continue
+		}
+		if value, ok := formatEnvValue(fv); ok {
+			env[name] = value
+		}
+	}
+}
+
+// 将字段的值转换为字符串，与 setEnvValue 相对应
+func formatEnvValue(v reflect.Value) (string, bool) {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), true
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true
+	}
+	return "", false
+}
@@ -0,0 +1,100 @@
+package configstore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type dotenvConfig struct {
+	Name     string
+	Port     int
+	Debug    bool
+	Ratio    float64
+	Timeout  time.Duration
+	Password string `configstore:"secret"`
+	DB       struct {
+		Host string
+		Port uint16
+	}
+}
+
+func TestExportImportEnv(t *testing.T) {
+	config := dotenvConfig{
+		Name:     "my \"app\" # $HOME\nline2",
+		Port:     8080,
+		Debug:    true,
+		Ratio:    0.75,
+		Timeout:  5 * time.Second,
+		Password: "hunter2",
+	}
+	config.DB.Host = "db.internal"
+	config.DB.Port = 5432
+
+	s, err := ExportEnv(config)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if strings.Contains(s, "PASSWORD") || strings.Contains(s, "hunter2") {
+		t.Errorf("Expected secret field to be omitted, but got: %s", s)
+	}
+	if !strings.Contains(s, "DB_HOST=") {
+		t.Errorf("Expected nested field key DB_HOST, but got: %s", s)
+	}
+
+	// 导出的内容可以被 godotenv 解析
+	env, err := godotenv.Unmarshal(s)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if env["NAME"] != config.Name || env["DB_PORT"] != "5432" || env["TIMEOUT"] != "5s" {
+		t.Errorf("Expected godotenv to parse exported values, but got: %v", env)
+	}
+
+	imported, err := ImportEnv[dotenvConfig](s)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	want := config
+	want.Password = ""
+	if imported != want {
+		t.Errorf("Expected %+v, but got: %+v", want, imported)
+	}
+
+	// WithIncludeSecrets 导出敏感字段
+	s, err = ExportEnv(config, WithIncludeSecrets())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	imported, err = ImportEnv[dotenvConfig](s)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if imported != config {
+		t.Errorf("Expected %+v, but got: %+v", config, imported)
+	}
+}
+
+func TestImportEnv(t *testing.T) {
+	s := "# comment\nexport NAME=app\nPORT='9090'\n\nDB_HOST=\"localhost\"\n"
+	config, err := ImportEnv[dotenvConfig](s)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Name != "app" || config.Port != 9090 || config.DB.Host != "localhost" {
+		t.Errorf("Expected imported values, but got: %+v", config)
+	}
+
+	if _, err := ImportEnv[dotenvConfig]("PORT=abc\n"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, but got: %v", err)
+	}
+	if _, err := ImportEnv[string]("NAME=app\n"); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption, but got: %v", err)
+	}
+	if _, err := ExportEnv("app"); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption, but got: %v", err)
+	}
+}
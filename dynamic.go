@@ -0,0 +1,40 @@
+package configstore
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetPath 读取配置并依次按 path 访问嵌套的值，用于 ConfigStore[map[string]any] 这样编译时不知道结构的配置。
+// 对象（map[string]any）按 key 访问，数组（[]any）按十进制下标访问。JSON 中的数字读取后是 float64，
+// null 对应 nil。path 为空时返回整个配置，路径不存在时返回 ErrKeyNotFound。
+func (cs *ConfigStore[T]) GetPath(path ...string) (any, error) {
+	config, err := cs.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	value, err := lookupPath(config, path)
+	return value, cs.wrapError("get", err)
+}
+
+// 从 root 开始依次访问 path 中的每一级
+func lookupPath(root any, path []string) (any, error) {
+	value := root
+	for i, name := range path {
+		var ok bool
+		switch v := value.(type) {
+		case map[string]any:
+			value, ok = v[name]
+		case []any:
+			index, err := strconv.Atoi(name)
+			if ok = err == nil && index >= 0 && index < len(v); ok {
+				value = v[index]
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, strings.Join(path[:i+1], "."))
+		}
+	}
+	return value, nil
+}
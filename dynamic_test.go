@@ -0,0 +1,129 @@
+package configstore
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDynamicConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dynamic.data")
+	cs, err := New[map[string]any](WithFile(filename), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := map[string]any{
+		"name":    "service",
+		"port":    8080,
+		"ratio":   0.75,
+		"enabled": true,
+		"missing": nil,
+		"tags":    []any{"a", "b"},
+		"database": map[string]any{
+			"hosts": []any{
+				map[string]any{"host": "db1", "port": 5432},
+				map[string]any{"host": "db2", "port": 5433},
+			},
+			"timeout": -1.5,
+		},
+	}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 使用新的 ConfigStore 读取，避免返回内存中的值
+	reopened, err := New[map[string]any](WithFile(filename), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loaded, err := reopened.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// JSON 中的数字读取后都是 float64
+	expected := map[string]any{
+		"name":    "service",
+		"port":    float64(8080),
+		"ratio":   0.75,
+		"enabled": true,
+		"missing": nil,
+		"tags":    []any{"a", "b"},
+		"database": map[string]any{
+			"hosts": []any{
+				map[string]any{"host": "db1", "port": float64(5432)},
+				map[string]any{"host": "db2", "port": float64(5433)},
+			},
+			"timeout": -1.5,
+		},
+	}
+	if !reflect.DeepEqual(loaded, expected) {
+		t.Errorf("Expected %v, but got: %v", expected, loaded)
+	}
+	if value, ok := loaded["missing"]; !ok || value != nil {
+		t.Errorf("Expected missing to be present with a nil value, but got: %v, %v", value, ok)
+	}
+
+	tests := []struct {
+		path     []string
+		expected any
+	}{
+		{[]string{"name"}, "service"},
+		{[]string{"port"}, float64(8080)},
+		{[]string{"ratio"}, 0.75},
+		{[]string{"missing"}, nil},
+		{[]string{"tags", "1"}, "b"},
+		{[]string{"database", "hosts", "0", "host"}, "db1"},
+		{[]string{"database", "hosts", "1", "port"}, float64(5433)},
+		{[]string{"database", "timeout"}, -1.5},
+	}
+	for _, tt := range tests {
+		value, err := reopened.GetPath(tt.path...)
+		if err != nil {
+			t.Errorf("Expected no error for %v, but got: %v", tt.path, err)
+			continue
+		}
+		if value != tt.expected {
+			t.Errorf("Expected %v for %v, but got: %v", tt.expected, tt.path, value)
+		}
+	}
+	if value, err := reopened.GetPath(); err != nil || !reflect.DeepEqual(value, expected) {
+		t.Errorf("Expected the whole config for an empty path, but got: %v, %v", value, err)
+	}
+
+	for _, path := range [][]string{
+		{"unknown"},
+		{"tags", "2"},
+		{"tags", "-1"},
+		{"tags", "first"},
+		{"name", "length"},
+		{"missing", "child"},
+	} {
+		if _, err := reopened.GetPath(path...); !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("Expected ErrKeyNotFound for %v, but got: %v", path, err)
+		}
+	}
+}
+
+func TestDynamicConfigEmpty(t *testing.T) {
+	cs, err := New[map[string]any](WithFile(filepath.Join(t.TempDir(), "dynamic.data")), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.GetPath("name"); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+	// 空对象和 nil 都可以保存
+	if err := cs.SaveConfig(map[string]any{}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded, err := cs.LoadConfig(); err != nil || loaded == nil || len(loaded) != 0 {
+		t.Errorf("Expected an empty map, but got: %v, %v", loaded, err)
+	}
+	if err := cs.SaveConfig(nil); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded, err := cs.LoadConfig(); err != nil || loaded != nil {
+		t.Errorf("Expected a nil map, but got: %v, %v", loaded, err)
+	}
+}
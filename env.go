@@ -0,0 +1,139 @@
+package configstore
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// WithEnvOverride 在读取配置后使用环境变量覆盖对应的字段，环境变量名为 PREFIX_FIELDNAME（大写），
+// 嵌套结构体使用 PREFIX_OUTER_INNER。字段名优先使用 json 标签中的名字（例如 `json:"http_port"` 对应 PREFIX_HTTP_PORT），
+// 名字中字母、数字以外的字符替换为 _；标记为 json:"-" 的字段被忽略，没有 json 名字的嵌入结构体的字段与 encoding/json 一样提升到外层。支持 string、整数、bool、浮点数和 time.Duration 类型的字段。
+// 环境变量只影响读取返回的值，不会被 SaveConfig 写入文件。T 必须是结构体。
+func WithEnvOverride(prefix string) Option {
+	return func(c *storeConfig) {
+		c.envOverride = true
+		c.envPrefix = prefix
+	}
+}
+
+// 检查 T 是否支持环境变量覆盖
+func checkEnvOverride[T any](cfg *storeConfig) error {
+	if !cfg.envOverride {
+		return nil
+	}
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: env override requires a struct config type, got %v", ErrInvalidOption, t)
+	}
+	return nil
+}
+
+// 使用环境变量覆盖 config 中的字段，返回覆盖后的副本
+func (cs *ConfigStore[T]) overrideEnv(config T) (T, error) {
+	if !cs.envOverride {
+		return config, nil
+	}
+	v := reflect.ValueOf(&config).Elem()
+	if err := applyEnv(v, strings.ToUpper(cs.envPrefix), os.LookupEnv); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// 使用 lookup 查找字段对应的变量并覆盖 v 中的字段
+func applyEnv(v reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isPromotedStruct(field) {
+			if err := applyEnv(v.Field(i), prefix, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+		jsonName, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		name := envName(prefix, jsonName)
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			if err := applyEnv(fv, name, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(fv, value); err != nil {
+			return fmt.Errorf("%w: environment variable %s: %w", ErrInvalidConfig, name, err)
+		}
+	}
+	return nil
+}
+
+func envName(prefix, name string) string {
+	name = strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(name))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// 与 encoding/json 一致，没有指定名字的嵌入结构体的字段提升到外层
+func isPromotedStruct(field reflect.StructField) bool {
+	return field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == ""
+}
+
+// 将环境变量的值转换为字段的类型，不支持的类型保持不变
+func setEnvValue(v reflect.Value, value string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
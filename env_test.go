@@ -0,0 +1,128 @@
+package configstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type envConfig struct {
+	Name    string
+	Port    int
+	Debug   bool
+	Ratio   float64
+	Timeout time.Duration
+	DB      struct {
+		Host string
+		Port int
+	}
+}
+
+func TestEnvOverride(t *testing.T) {
+	backend := NewMemoryBackend()
+	cs, err := NewConfigStore[envConfig]("", "0123456789abcdef", WithBackend(backend), WithEnvOverride("app"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	stored := envConfig{Name: "stored", Port: 80, Ratio: 0.5, Timeout: time.Second}
+	stored.DB.Host = "localhost"
+	stored.DB.Port = 5432
+	if err := cs.SaveConfig(stored); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("APP_RATIO", "0.75")
+	t.Setenv("APP_TIMEOUT", "5s")
+	t.Setenv("APP_DB_HOST", "db.internal")
+	config, err := cs.LoadConfigOrDefault(envConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := stored
+	expected.Port = 8080
+	expected.Debug = true
+	expected.Ratio = 0.75
+	expected.Timeout = 5 * time.Second
+	expected.DB.Host = "db.internal"
+	if config != expected {
+		t.Errorf("Expected config to be %+v, but got: %+v", expected, config)
+	}
+
+	// SaveConfig 不使用环境变量，文件中仍然是原来的值
+	plain, err := NewConfigStore[envConfig]("", "0123456789abcdef", WithBackend(backend))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(stored); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config, _ := plain.LoadConfigOrDefault(envConfig{}); config != stored {
+		t.Errorf("Expected stored config to be %+v, but got: %+v", stored, config)
+	}
+}
+
+func TestEnvOverrideDefault(t *testing.T) {
+	cs, err := NewConfigStore[envConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()), WithEnvOverride("APP"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	t.Setenv("APP_NAME", "from-env")
+	config, err := cs.LoadConfigOrDefault(envConfig{Name: "default", Port: 80})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Name != "from-env" || config.Port != 80 {
+		t.Errorf("Expected env to override the default config, but got: %+v", config)
+	}
+
+	t.Setenv("APP_PORT", "not-a-number")
+	if _, err := cs.LoadConfigOrDefault(envConfig{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, but got: %v", err)
+	}
+}
+
+type envBase struct {
+	Region string `json:"region"`
+}
+
+type envTaggedConfig struct {
+	envBase
+	Port    int    `json:"http_port"`
+	Name    string `json:"service-name,omitempty"`
+	Ignored string `json:"-"`
+	Server  struct {
+		Host string `json:"host_name"`
+	} `json:"server"`
+}
+
+func TestEnvOverrideJSONNames(t *testing.T) {
+	cs, err := New[envTaggedConfig](WithBackend(NewMemoryBackend()), WithKey("0123456789abcdef"), WithEnvOverride("APP"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	t.Setenv("APP_HTTP_PORT", "8080")
+	t.Setenv("APP_PORT", "9090")
+	t.Setenv("APP_SERVICE_NAME", "api")
+	t.Setenv("APP_IGNORED", "set")
+	t.Setenv("APP_SERVER_HOST_NAME", "db.internal")
+	t.Setenv("APP_REGION", "eu-west-1")
+	config, err := cs.LoadConfigOrDefault(envTaggedConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Port != 8080 || config.Name != "api" || config.Ignored != "" || config.Server.Host != "db.internal" || config.Region != "eu-west-1" {
+		t.Errorf("Expected env to use JSON names, but got: %+v", config)
+	}
+
+	// ExportEnv 使用相同的变量名
+	s, err := ExportEnv(config)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	imported, err := ImportEnv[envTaggedConfig](s)
+	if err != nil || imported != config {
+		t.Errorf("Expected %+v, but got: %+v, %v (from %q)", config, imported, err, s)
+	}
+}
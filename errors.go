@@ -0,0 +1,113 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrIntegrityFailure 表示文件的 HMAC 校验失败，数据可能被篡改或损坏
+var ErrIntegrityFailure = errors.New("configstore: integrity check failed")
+
+// ErrUnsupportedVersion 表示文件格式版本高于当前库支持的版本
+var ErrUnsupportedVersion = errors.New("configstore: unsupported file format version")
+
+// ErrNoConfig 表示还没有保存过配置（文件不存在或为空）
+var ErrNoConfig = errors.New("configstore: no config has been saved")
+
+// ErrInvalidConfig 表示配置没有通过 WithValidator 设置的校验
+var ErrInvalidConfig = errors.New("configstore: invalid config")
+
+// ErrStoreClosed 表示 ConfigStore 已经通过 Close 关闭
+var ErrStoreClosed = errors.New("configstore: store is closed")
+
+// ErrInvalidKeyLength 表示 key 的长度不适用于当前的加密模式
+var ErrInvalidKeyLength = errors.New("configstore: invalid key length")
+
+// ErrFileNotFound 表示需要的文件不存在（例如指定的备份），可以同时用 errors.Is 与 os.ErrNotExist 比较
+var ErrFileNotFound = fmt.Errorf("configstore: file not found: %w", os.ErrNotExist)
+
+// ErrDecryptionFailed 表示解密失败，通常是 key 错误或密文被篡改
+var ErrDecryptionFailed = errors.New("configstore: decryption failed")
+
+// ErrCorruptData 表示文件内容无法解析：长度不正确、头部损坏、解压或反序列化失败
+var ErrCorruptData = errors.New("configstore: corrupt data")
+
+// ErrStoreMismatch 表示文件的格式或 key 类型与当前 ConfigStore 的配置不一致
+var ErrStoreMismatch = errors.New("configstore: file does not match store configuration")
+
+// ErrInvalidOption 表示创建 ConfigStore 时传入的参数或 Option 无效
+var ErrInvalidOption = errors.New("configstore: invalid option")
+
+// ErrUnsupported 表示不支持的加密模式、压缩算法、序列化格式、派生算法或后端操作
+var ErrUnsupported = errors.New("configstore: unsupported")
+
+// ErrConflict 表示写入时发现数据已经被其他客户端修改，需要重新读取后再写入
+var ErrConflict = errors.New("configstore: write conflict")
+
+// ErrChecksumMismatch 表示配置文件与 WithChecksumFile 写入的校验文件不一致
+var ErrChecksumMismatch = errors.New("configstore: checksum mismatch")
+
+// ErrExternalModification 表示配置文件在上一次读取之后被其他进程修改，保存会覆盖该修改
+var ErrExternalModification = errors.New("configstore: config file modified externally")
+
+// ErrConfigExpired 表示保存的配置已经超过 WithMaxAge 设置的有效期，需要从数据源刷新后重新保存
+var ErrConfigExpired = errors.New("configstore: config expired")
+
+// ErrTimeout 表示操作没有在 WithDefaultTimeout 设置的时间内完成，可以同时用 errors.Is 与 context.DeadlineExceeded 比较
+var ErrTimeout = fmt.Errorf("configstore: operation timed out: %w", context.DeadlineExceeded)
+
+// ErrCircuitOpen 表示 WithCircuitBreaker 设置的断路器已经打开，没有访问存储后端
+var ErrCircuitOpen = errors.New("configstore: circuit breaker is open")
+
+// ErrKeyNotFound 表示 KVStore 中不存在请求的 key，或 GetPath 访问的路径不存在
+var ErrKeyNotFound = errors.New("configstore: key not found")
+
+// ErrDuplicateKey 表示 Table 中已经存在相同主键的行
+var ErrDuplicateKey = errors.New("configstore: duplicate primary key")
+
+// ErrNotProtoMessage 表示使用 ProtoCodec 时配置类型没有实现 proto.Message
+var ErrNotProtoMessage = errors.New("configstore: config type is not a proto.Message")
+
+// ErrKeyUnavailable 表示 KeyProvider 无法提供 key
+var ErrKeyUnavailable = errors.New("configstore: key unavailable")
+
+// StoreError 记录出错的操作和配置文件，公开方法返回的错误都会包装为 *StoreError。
+// 可以通过 errors.Is 与上面的错误比较，或者通过 errors.As 取得 Op 和 File。
+type StoreError struct {
+	// Op 是出错的操作，例如 "load"、"save"、"rotate"
+	Op string
+	// File 是配置文件的路径，不使用 FileBackend 时为空
+	File string
+	Err  error
+}
+
+func (e *StoreError) Error() string {
+	if e.File == "" {
+		return "configstore: " + e.Op + ": " + e.Err.Error()
+	}
+	return "configstore: " + e.Op + " " + e.File + ": " + e.Err.Error()
+}
+
+func (e *StoreError) Unwrap() error {
+	return e.Err
+}
+
+// 将 err 包装为 *StoreError，err 为 nil 或已经是 *StoreError 时原样返回
+func newStoreError(op, file string, err error) error {
+	var se *StoreError
+	if err == nil || errors.As(err, &se) {
+		return err
+	}
+	return &StoreError{Op: op, File: file, Err: err}
+}
+
+// 使用当前配置文件的路径包装 err
+func (cs *ConfigStore[T]) wrapError(op string, err error) error {
+	var file string
+	if fb, ok := cs.fileBackend(); ok {
+		file = fb.filename
+	}
+	return newStoreError(op, file, err)
+}
@@ -0,0 +1,83 @@
+package configstore
+
+import (
+	"context"
+	"sync"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdBackend 将配置数据保存在 etcd 的一个 key 中，并使用乐观并发控制防止丢失更新：
+// 每次读取后记录 key 的 ModRevision，写入时通过事务比较 ModRevision，key 在此期间被其他客户端修改时返回 ErrConflict。
+// 还没有读取过时，只有 key 不存在才能写入成功，因此通常先调用 LoadConfig，或者使用 UpdateConfig。
+type EtcdBackend struct {
+	client *clientv3.Client
+	key    string
+
+	mu       sync.Mutex
+	revision int64
+}
+
+// NewEtcdBackend 创建一个保存到 etcd 中 key 的 EtcdBackend
+func NewEtcdBackend(client *clientv3.Client, key string) *EtcdBackend {
+	return &EtcdBackend{client: client, key: key}
+}
+
+func (b *EtcdBackend) Read() ([]byte, error) {
+	resp, err := b.client.Get(context.Background(), b.key)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(resp.Kvs) == 0 {
+		b.revision = 0
+		return nil, nil
+	}
+	b.revision = resp.Kvs[0].ModRevision
+	return resp.Kvs[0].Value, nil
+}
+
+func (b *EtcdBackend) Write(data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// 不存在的 key 的 ModRevision 为 0
+	resp, err := b.client.Txn(context.Background()).
+		If(clientv3.Compare(clientv3.ModRevision(b.key), "=", b.revision)).
+		Then(clientv3.OpPut(b.key, string(data))).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return ErrConflict
+	}
+	b.revision = resp.Header.Revision
+	return nil
+}
+
+func (b *EtcdBackend) Delete() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.client.Delete(context.Background(), b.key); err != nil {
+		return err
+	}
+	b.revision = 0
+	return nil
+}
+
+// Watch 使用 etcd 的 watch API 监听 key 的修改和删除
+func (b *EtcdBackend) Watch(ctx context.Context, onChange func()) error {
+	for resp := range b.client.Watch(clientv3.WithRequireLeader(ctx), b.key) {
+		if err := resp.Err(); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if len(resp.Events) > 0 {
+			onChange()
+		}
+	}
+	return nil
+}
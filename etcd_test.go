@@ -0,0 +1,151 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// 启动一个嵌入式 etcd，返回连接到它的客户端
+func newTestEtcd(t *testing.T) *clientv3.Client {
+	t.Helper()
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	clientURL, _ := url.Parse("http://127.0.0.1:0")
+	peerURL, _ := url.Parse("http://127.0.0.1:0")
+	cfg.ListenClientUrls = []url.URL{*clientURL}
+	cfg.ListenPeerUrls = []url.URL{*peerURL}
+
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	t.Cleanup(e.Close)
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected etcd to be ready")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{e.Clients[0].Addr().String()},
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestEtcdBackend(t *testing.T) {
+	client := newTestEtcd(t)
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewEtcdBackend(client, "/app/config")))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+
+	config := myConfig{Username: "testuser", Password: "testpassword"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 连续写入时使用上一次写入的 revision
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected %+v, but got: %+v", config, loadConfig)
+	}
+
+	if err := cs.DeleteConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+}
+
+func TestEtcdBackendConflict(t *testing.T) {
+	client := newTestEtcd(t)
+	cs1, _ := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewEtcdBackend(client, "/app/config")))
+	cs2, _ := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewEtcdBackend(client, "/app/config")))
+	if err := cs1.SaveConfig(myConfig{Username: "v1"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 两个客户端都读取到 v1
+	if _, err := cs1.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs2.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs2.SaveConfig(myConfig{Username: "v2"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// cs1 基于旧的 revision 写入，不应覆盖 cs2 的修改
+	if err := cs1.SaveConfig(myConfig{Username: "v3"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, but got: %v", err)
+	}
+
+	// 重新读取后可以继续写入
+	err := cs1.UpdateConfig(func(c myConfig) (myConfig, error) {
+		if c.Username != "v2" {
+			t.Errorf("Expected username v2, but got: %s", c.Username)
+		}
+		c.Username = "v3"
+		return c, nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+}
+
+func TestEtcdBackendWatch(t *testing.T) {
+	client := newTestEtcd(t)
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewEtcdBackend(client, "/app/config")))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	other, _ := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewEtcdBackend(client, "/app/config")))
+
+	changes := make(chan myConfig, 4)
+	cancel, err := cs.Watch(context.Background(), func(config myConfig, err error) {
+		if err != nil {
+			t.Errorf("Expected no error, but got: %v", err)
+			return
+		}
+		changes <- config
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defer cancel()
+
+	// 等待 watch 建立后再写入
+	time.Sleep(100 * time.Millisecond)
+	if err := other.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	select {
+	case config := <-changes:
+		if config.Username != "testuser" {
+			t.Errorf("Expected username testuser, but got: %s", config.Username)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a change notification")
+	}
+}
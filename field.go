@@ -0,0 +1,165 @@
+package configstore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// 通过 HKDF 派生字段加密 key 时使用的 info
+const fieldKeyInfo = "configstore field aes-256-gcm"
+
+// fieldTree 以 JSON 字段名记录需要加密的字段，值为 nil 表示加密该字段，否则继续处理嵌套的对象
+type fieldTree map[string]fieldTree
+
+// 收集 t 中带有 configstore:"encrypt" 标签的字段，没有时返回 nil
+func encryptedFields(t reflect.Type) fieldTree {
+	return collectEncryptedFields(t, map[reflect.Type]bool{})
+}
+
+func collectEncryptedFields(t reflect.Type, visiting map[reflect.Type]bool) fieldTree {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// 递归类型只处理一层
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var tree fieldTree
+	add := func(name string, sub fieldTree) {
+		if tree == nil {
+			tree = fieldTree{}
+		}
+		tree[name] = sub
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if hasTagOption(field, "encrypt") {
+			if name, ok := jsonFieldName(field); ok {
+				add(name, nil)
+			}
+			continue
+		}
+		// 没有指定名字的嵌入结构体的字段与外层在同一个 JSON 对象中
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			for name, sub := range collectEncryptedFields(field.Type, visiting) {
+				add(name, sub)
+			}
+			continue
+		}
+		if sub := collectEncryptedFields(field.Type, visiting); sub != nil {
+			if name, ok := jsonFieldName(field); ok {
+				add(name, sub)
+			}
+		}
+	}
+	return tree
+}
+
+// 判断字段的 configstore 标签中是否包含 option
+func hasTagOption(field reflect.StructField, option string) bool {
+	for _, opt := range strings.Split(field.Tag.Get("configstore"), ",") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// 对 JSON 数据中 tree 指定的字段依次调用 fn
+func (tree fieldTree) apply(data []byte, fn func([]byte) ([]byte, error)) ([]byte, error) {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return data, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptData, err)
+	}
+	for name, sub := range tree {
+		value, ok := obj[name]
+		if !ok {
+			continue
+		}
+		var err error
+		if sub == nil {
+			value, err = fn(value)
+		} else {
+			value, err = sub.apply(value, fn)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		obj[name] = value
+	}
+	return json.Marshal(obj)
+}
+
+func newFieldAEAD(key []byte) (cipher.AEAD, error) {
+	fieldKey, err := hkdf.Key(sha256.New, key, nil, fieldKeyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(fieldKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// 将需要加密的字段替换为 base64 编码的 [nonce][密文] 字符串，null 保持不变
+func (tree fieldTree) encrypt(key []byte, data []byte, random io.Reader) ([]byte, error) {
+	aead, err := newFieldAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return tree.apply(data, func(value []byte) ([]byte, error) {
+		if bytes.Equal(value, []byte("null")) {
+			return value, nil
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := io.ReadFull(random, nonce); err != nil {
+			return nil, err
+		}
+		return json.Marshal(base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, value, nil)))
+	})
+}
+
+// 解密 encrypt 的输出，还原字段原来的 JSON 值
+func (tree fieldTree) decrypt(key []byte, data []byte) ([]byte, error) {
+	aead, err := newFieldAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return tree.apply(data, func(value []byte) ([]byte, error) {
+		if bytes.Equal(value, []byte("null")) {
+			return value, nil
+		}
+		var encoded string
+		if err := json.Unmarshal(value, &encoded); err != nil {
+			return nil, fmt.Errorf("%w: encrypted field is not a string", ErrCorruptData)
+		}
+		sealed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptData, err)
+		}
+		if len(sealed) < aead.NonceSize() {
+			return nil, errInvalidCiphertext
+		}
+		plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
+		}
+		return plaintext, nil
+	})
+}
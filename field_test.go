@@ -0,0 +1,90 @@
+package configstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type secretConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password" configstore:"encrypt"`
+	Database struct {
+		Host   string `json:"host"`
+		APIKey string `json:"api_key" configstore:"encrypt"`
+	} `json:"database"`
+	Ports []int `json:"ports" configstore:"encrypt"`
+}
+
+func TestFieldEncryption(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "fields.data")
+	cs, err := NewConfigStore[secretConfig](filename, "0123456789abcdef", WithCipherMode(CipherModeNone))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := secretConfig{Username: "testuser", Password: "testpassword", Ports: []int{80, 443}}
+	config.Database.Host = "db.internal"
+	config.Database.APIKey = "secret-api-key"
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 只有标记的字段被加密，其余字段可以直接查看
+	data, _ := os.ReadFile(filename)
+	for _, plain := range []string{"testuser", "db.internal"} {
+		if !bytes.Contains(data, []byte(plain)) {
+			t.Errorf("Expected %q to be stored in plaintext", plain)
+		}
+	}
+	for _, secret := range []string{"testpassword", "secret-api-key", "443"} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("Expected %q to be encrypted", secret)
+		}
+	}
+
+	loadConfig, err := cs.LoadConfigOrDefault(secretConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig.Password != config.Password || loadConfig.Database != config.Database || len(loadConfig.Ports) != 2 {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+
+	// key 错误时字段无法解密
+	other, err := NewConfigStore[secretConfig](filename, "fedcba9876543210", WithCipherMode(CipherModeNone))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := other.LoadConfig(); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed, but got: %v", err)
+	}
+}
+
+func TestFieldEncryptionWithPassword(t *testing.T) {
+	// 字段级加密与整个文件的加密可以同时使用
+	filename := filepath.Join(t.TempDir(), "fields.data")
+	cs, err := NewConfigStoreFromPassword[secretConfig](filename, "password", WithPBKDF2Iterations(1000), WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := secretConfig{Username: "testuser", Password: "testpassword"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(secretConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig.Username != config.Username || loadConfig.Password != config.Password {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestFieldEncryptionRequiresJSON(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "fields.data")
+	if _, err := NewConfigStore[secretConfig](filename, "0123456789abcdef", WithFormat(FormatYAML)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, but got: %v", err)
+	}
+}
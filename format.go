@@ -0,0 +1,206 @@
+package configstore
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec 负责配置的序列化和反序列化，可以通过 WithCodec 接入 Protocol Buffers、FlatBuffers 等格式
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// SerializationFormat 表示配置在加密前使用的序列化格式
+type SerializationFormat uint8
+
+const (
+	// FormatJSON 使用 encoding/json，是默认格式
+	FormatJSON SerializationFormat = iota
+	// FormatTOML 使用 github.com/BurntSushi/toml，配置类型需要是结构体或 map
+	FormatTOML
+	// FormatYAML 使用 gopkg.in/yaml.v3，字段遵循结构体上的 yaml 标签（包括 omitempty）
+	FormatYAML
+	// FormatMessagePack 使用 github.com/vmihailenco/msgpack/v5，结构体按字段名编码为 map，
+	// 没有 msgpack 标签时使用 json 标签。新增的字段在读取旧数据时为零值，与 JSON 的行为一致。
+	FormatMessagePack
+	// FormatGob 使用 encoding/gob，输出比 JSON 更小，只适用于 Go 程序内部使用的配置。
+	// 注意 gob 数据与 Go 的类型定义紧密相关，不保证跨 Go 版本兼容，也无法被其他语言读取。
+	FormatGob
+	// FormatBinary 使用配置类型实现的 encoding.BinaryMarshaler 和 encoding.BinaryUnmarshaler，
+	// 适用于 JSON 表示不方便或有损的类型。同时实现了 json.Marshaler 的类型默认仍然使用 JSON，
+	// 只有显式设置 FormatBinary 时才使用 MarshalBinary。配置类型没有实现这两个接口时 New 返回 ErrUnsupported。
+	FormatBinary
+
+	// FormatCustom 表示通过 WithCodec 设置的自定义 Codec
+	FormatCustom SerializationFormat = 0xff
+)
+
+func (f SerializationFormat) String() string {
+	switch f {
+	case FormatJSON:
+		return "JSON"
+	case FormatTOML:
+		return "TOML"
+	case FormatYAML:
+		return "YAML"
+	case FormatMessagePack:
+		return "MessagePack"
+	case FormatGob:
+		return "gob"
+	case FormatBinary:
+		return "binary"
+	case FormatCustom:
+		return "custom"
+	default:
+		return "unknown"
+	}
+}
+
+// 返回内置格式对应的 Codec
+func (f SerializationFormat) codec() (Codec, error) {
+	switch f {
+	case FormatJSON:
+		return jsonCodec{}, nil
+	case FormatTOML:
+		return tomlCodec{}, nil
+	case FormatYAML:
+		return yamlCodec{}, nil
+	case FormatMessagePack:
+		return msgpackCodec{}, nil
+	case FormatGob:
+		return gobCodec{}, nil
+	case FormatBinary:
+		return binaryCodec{}, nil
+	default:
+		return nil, fmt.Errorf("%w serialization format %d", ErrUnsupported, f)
+	}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type tomlCodec struct{}
+
+func (tomlCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (tomlCodec) Unmarshal(data []byte, v any) error {
+	return toml.Unmarshal(data, v)
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) Marshal(v any) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+func (yamlCodec) Unmarshal(data []byte, v any) error {
+	return yaml.Unmarshal(data, v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+var (
+	binaryMarshalerType   = reflect.TypeFor[encoding.BinaryMarshaler]()
+	binaryUnmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+)
+
+// 检查 T 是否可以使用 FormatBinary，方法可以定义在 T 或 *T 上
+func checkBinaryFormat[T any]() error {
+	typ := reflect.TypeFor[T]()
+	ptr := reflect.PointerTo(typ)
+	if !typ.Implements(binaryMarshalerType) && !ptr.Implements(binaryMarshalerType) {
+		return fmt.Errorf("%w: %v does not implement encoding.BinaryMarshaler", ErrUnsupported, typ)
+	}
+	if !ptr.Implements(binaryUnmarshalerType) && (typ.Kind() != reflect.Pointer || !typ.Implements(binaryUnmarshalerType)) {
+		return fmt.Errorf("%w: %v does not implement encoding.BinaryUnmarshaler", ErrUnsupported, typ)
+	}
+	return nil
+}
+
+type binaryCodec struct{}
+
+func (binaryCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(encoding.BinaryMarshaler)
+	if !ok && v != nil {
+		// MarshalBinary 定义在指针上时复制到一个新的指针
+		p := reflect.New(reflect.TypeOf(v))
+		p.Elem().Set(reflect.ValueOf(v))
+		m, ok = p.Interface().(encoding.BinaryMarshaler)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not implement encoding.BinaryMarshaler", ErrUnsupported, v)
+	}
+	if rv := reflect.ValueOf(m); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, fmt.Errorf("%w: cannot marshal nil %T", ErrInvalidConfig, v)
+	}
+	return m.MarshalBinary()
+}
+
+func (binaryCodec) Unmarshal(data []byte, v any) error {
+	u, ok := v.(encoding.BinaryUnmarshaler)
+	if !ok {
+		// 配置类型本身是指针时，v 是指向该指针的指针，需要先分配
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.Elem().Kind() == reflect.Pointer {
+			if rv.Elem().IsNil() {
+				rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+			}
+			u, ok = rv.Elem().Interface().(encoding.BinaryUnmarshaler)
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%w: %T does not implement encoding.BinaryUnmarshaler", ErrUnsupported, v)
+	}
+	return u.UnmarshalBinary(data)
+}
@@ -0,0 +1,377 @@
+package configstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type serverConfig struct {
+	Name    string   `json:"name" toml:"name"`
+	Port    int      `json:"port" toml:"port"`
+	Hosts   []string `json:"hosts" toml:"hosts"`
+	Enabled bool     `json:"enabled" toml:"enabled"`
+}
+
+func TestTOMLSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "toml.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[serverConfig](filename, key, WithFormat(FormatTOML))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := serverConfig{Name: "api", Port: 8080, Hosts: []string{"a.example.com", "b.example.com"}, Enabled: true}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(serverConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !reflect.DeepEqual(loadConfig, config) {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+
+	// 解密后的内容是 TOML
+	data, _ := os.ReadFile(filename)
+	plaintext, _, err := cs.open([]byte(key), data)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !bytes.Contains(plaintext, []byte(`name = "api"`)) {
+		t.Errorf("Expected TOML output, but got: %s", plaintext)
+	}
+}
+
+func TestFormatMismatch(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "toml.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[serverConfig](filename, key, WithFormat(FormatTOML))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(serverConfig{Name: "api"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	cs2, err := NewConfigStore[serverConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	_, err = cs2.LoadConfigOrDefault(serverConfig{})
+	if !errors.Is(err, ErrStoreMismatch) || !strings.Contains(err.Error(), "format mismatch") {
+		t.Errorf("Expected a format mismatch error, but got: %v", err)
+	}
+}
+
+type scheduleConfig struct {
+	Name        string    `yaml:"name"`
+	Description string    `yaml:"description,omitempty"`
+	StartAt     time.Time `yaml:"start_at"`
+	Tags        []string  `yaml:"tags,omitempty"`
+}
+
+func TestYAMLSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "yaml.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[scheduleConfig](filename, key, WithFormat(FormatYAML))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// YAML 时间戳需要保留纳秒和时区
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 123456789, time.FixedZone("UTC+8", 8*3600))
+	config := scheduleConfig{Name: "nightly", Description: "line one\nline two\n", StartAt: startAt}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(scheduleConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !loadConfig.StartAt.Equal(startAt) {
+		t.Errorf("Expected start time to be %v, but got: %v", startAt, loadConfig.StartAt)
+	}
+	if loadConfig.Name != config.Name || loadConfig.Description != config.Description {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+
+	// 空字段遵循 omitempty
+	data, _ := os.ReadFile(filename)
+	plaintext, _, err := cs.open([]byte(key), data)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if bytes.Contains(plaintext, []byte("tags")) {
+		t.Errorf("Expected empty tags to be omitted, but got: %s", plaintext)
+	}
+}
+
+type calibrationV1 struct {
+	Sensor  string    `json:"sensor"`
+	Offsets []float64 `json:"offsets"`
+}
+
+type calibrationV2 struct {
+	Sensor  string    `json:"sensor"`
+	Offsets []float64 `json:"offsets"`
+	Gain    float64   `json:"gain"`
+}
+
+func TestMessagePackSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "msgpack.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[calibrationV1](filename, key, WithFormat(FormatMessagePack))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := calibrationV1{Sensor: "imu-0", Offsets: []float64{0.125, -1.5, 3}}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(calibrationV1{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !reflect.DeepEqual(loadConfig, config) {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+
+	// 结构体新增字段后，读取旧数据时新字段为零值
+	cs2, err := NewConfigStore[calibrationV2](filename, key, WithFormat(FormatMessagePack))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig2, err := cs2.LoadConfigOrDefault(calibrationV2{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig2.Sensor != config.Sensor || !reflect.DeepEqual(loadConfig2.Offsets, config.Offsets) || loadConfig2.Gain != 0 {
+		t.Errorf("Unexpected config: %+v", loadConfig2)
+	}
+}
+
+type treeNode struct {
+	Name     string
+	Children []*treeNode
+}
+
+func TestGobSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "gob.data")
+	cs, err := NewConfigStore[treeNode](filename, "0123456789abcdef", WithFormat(FormatGob))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// gob 支持递归类型
+	config := treeNode{Name: "root", Children: []*treeNode{{Name: "a"}, {Name: "b", Children: []*treeNode{{Name: "c"}}}}}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(treeNode{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !reflect.DeepEqual(loadConfig, config) {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+// 在 JSON 外面包一层前缀的自定义 Codec
+type prefixCodec struct {
+	marshalCalls   int
+	unmarshalCalls int
+}
+
+func (c *prefixCodec) Marshal(v any) ([]byte, error) {
+	c.marshalCalls++
+	data, err := json.Marshal(v)
+	return append([]byte("custom:"), data...), err
+}
+
+func (c *prefixCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshalCalls++
+	return json.Unmarshal(bytes.TrimPrefix(data, []byte("custom:")), v)
+}
+
+func TestCustomCodec(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "custom.data")
+	key := "0123456789abcdef"
+	codec := &prefixCodec{}
+	cs, err := NewConfigStore[myConfig](filename, key, WithCodec(codec))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+	if codec.marshalCalls != 1 || codec.unmarshalCalls != 1 {
+		t.Errorf("Expected codec to be used once each, but got: %d marshal, %d unmarshal", codec.marshalCalls, codec.unmarshalCalls)
+	}
+
+	data, _ := os.ReadFile(filename)
+	header, _, err := parseFileHeader(data)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if header.format != FormatCustom {
+		t.Errorf("Expected format to be %v, but got: %v", FormatCustom, header.format)
+	}
+}
+
+// 同时实现 json.Marshaler 和 encoding.BinaryMarshaler 的类型
+type binaryPoint struct {
+	X, Y int32
+}
+
+func (p binaryPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]int32{p.X, p.Y})
+}
+
+func (p *binaryPoint) UnmarshalJSON(data []byte) error {
+	var xy [2]int32
+	if err := json.Unmarshal(data, &xy); err != nil {
+		return err
+	}
+	p.X, p.Y = xy[0], xy[1]
+	return nil
+}
+
+func (p binaryPoint) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, uint32(p.X)), uint32(p.Y)), nil
+}
+
+func (p *binaryPoint) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return errors.New("invalid point")
+	}
+	p.X, p.Y = int32(binary.BigEndian.Uint32(data)), int32(binary.BigEndian.Uint32(data[4:]))
+	return nil
+}
+
+func TestBinarySaveAndLoad(t *testing.T) {
+	key := "0123456789abcdef"
+	config := binaryPoint{X: 3, Y: -4}
+	expected, _ := config.MarshalBinary()
+	for _, tt := range []struct {
+		opts      []Option
+		format    SerializationFormat
+		plaintext []byte
+	}{
+		// 默认 JSON 优先
+		{nil, FormatJSON, []byte("[3,-4]")},
+		{[]Option{WithFormat(FormatBinary)}, FormatBinary, expected},
+	} {
+		filename := filepath.Join(t.TempDir(), "binary.data")
+		cs, err := NewConfigStore[binaryPoint](filename, key, tt.opts...)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := cs.SaveConfig(config); err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", tt.format, err)
+		}
+		if loaded, err := cs.LoadConfig(); err != nil || loaded != config {
+			t.Errorf("%s: expected %+v, but got: %+v, %v", tt.format, config, loaded, err)
+		}
+
+		data, _ := os.ReadFile(filename)
+		plaintext, header, err := cs.open([]byte(key), data)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if header.format != tt.format || !bytes.Equal(plaintext, tt.plaintext) {
+			t.Errorf("Expected %s output %q, but got: %s %q", tt.format, tt.plaintext, header.format, plaintext)
+		}
+	}
+}
+
+func TestBinaryStandardTypes(t *testing.T) {
+	// UnmarshalBinary 定义在指针上的值类型
+	addrs, err := NewConfigStore[netip.Addr]("", "0123456789abcdef", WithBackend(NewMemoryBackend()), WithFormat(FormatBinary))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	addr := netip.MustParseAddr("2001:db8::1")
+	if err := addrs.SaveConfig(addr); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded, err := addrs.LoadConfig(); err != nil || loaded != addr {
+		t.Errorf("Expected %v, but got: %v, %v", addr, loaded, err)
+	}
+
+	// 配置类型本身是指针
+	urls, err := NewConfigStore[*url.URL]("", "0123456789abcdef", WithBackend(NewMemoryBackend()), WithFormat(FormatBinary))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := urls.SaveConfig(nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for a nil pointer, but got: %v", err)
+	}
+	u, _ := url.Parse("https://example.com/path?q=1")
+	if err := urls.SaveConfig(u); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded, err := urls.LoadConfig(); err != nil || loaded.String() != u.String() {
+		t.Errorf("Expected %v, but got: %v, %v", u, loaded, err)
+	}
+
+	// 没有实现 encoding.BinaryMarshaler 的类型
+	if _, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()), WithFormat(FormatBinary)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, but got: %v", err)
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "unknown.data")
+	if _, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithFormat(SerializationFormat(100))); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected an error for an unsupported format, but got nil")
+	}
+}
+
+type timeConfig struct {
+	Name      string     `json:"name" toml:"name" yaml:"name"`
+	CreatedAt time.Time  `json:"created_at" toml:"created_at" yaml:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" toml:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+}
+
+// time.Time 在所有格式中都应保留纳秒精度，时区可能变为固定偏移或 UTC，因此使用 Equal 比较
+func TestTimeRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.FixedZone("CST", 8*3600))
+	// time.Now 带有单调时钟读数，序列化后会丢失，== 比较会失败
+	expires := time.Now()
+
+	for _, format := range []SerializationFormat{FormatJSON, FormatTOML, FormatYAML, FormatMessagePack, FormatGob} {
+		cs, err := NewConfigStore[timeConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()), WithFormat(format))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := cs.SaveConfig(timeConfig{Name: "app", CreatedAt: created, ExpiresAt: &expires}); err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", format, err)
+		}
+		config, err := cs.LoadConfig()
+		if err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", format, err)
+		}
+		if !config.CreatedAt.Equal(created) {
+			t.Errorf("%s: expected created_at %v, but got: %v", format, created, config.CreatedAt)
+		}
+		if config.ExpiresAt == nil || !config.ExpiresAt.Equal(expires) {
+			t.Errorf("%s: expected expires_at %v, but got: %v", format, expires, config.ExpiresAt)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Group 管理使用同一个 key 的多个命名 ConfigStore，例如主配置、凭据和功能开关，
+// 可以一次性读取、保存所有配置或轮换所有配置的 key
+type Group struct {
+	mu     sync.Mutex
+	key    string
+	stores map[string]groupMember
+}
+
+// Group 对不同类型的 ConfigStore 的统一操作
+type groupMember interface {
+	Refresh() error
+	RotateKey(newKey string) error
+	Close() error
+	saveCached() error
+}
+
+// NewGroup 创建一个使用 key 的 Group，通过 Add 添加配置
+func NewGroup(key string) *Group {
+	return &Group{key: key, stores: make(map[string]groupMember)}
+}
+
+// Add 在 g 中以 name 注册一个保存到 filename 的 ConfigStore，使用 g 的 key 并启用缓存，
+// opts 用于设置其他 Option。name 已经存在时返回 ErrInvalidOption。
+func Add[T any](g *Group, name, filename string, opts ...Option) (*ConfigStore[T], error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.stores[name]; ok {
+		return nil, fmt.Errorf("%w: store %q already exists in group", ErrInvalidOption, name)
+	}
+	cs, err := New[T](append([]Option{WithFile(filename), WithKey(g.key), WithCache()}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	g.stores[name] = cs
+	return cs, nil
+}
+
+// LoadAll 读取所有配置到各自的缓存中，还没有保存过的配置会被跳过，有失败时返回 *MultiError
+func (g *Group) LoadAll() error {
+	return g.each(func(m groupMember) error {
+		if err := m.Refresh(); err != nil && !errors.Is(err, ErrNoConfig) {
+			return err
+		}
+		return nil
+	})
+}
+
+// SaveAll 并发地将所有配置缓存中的值重新加密保存，没有缓存的配置会被跳过，有失败时返回 *MultiError
+func (g *Group) SaveAll() error {
+	return g.each(func(m groupMember) error {
+		return m.saveCached()
+	})
+}
+
+// RotateKey 将所有配置轮换为 newKey。某个配置轮换失败时，已经轮换的配置会被轮换回原来的 key，
+// 返回的 *MultiError 中包含失败的配置以及无法回滚的配置。
+func (g *Group) RotateKey(newKey string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var rotated []string
+	for _, name := range g.names() {
+		err := g.stores[name].RotateKey(newKey)
+		if err == nil {
+			rotated = append(rotated, name)
+			continue
+		}
+		errs := []error{fmt.Errorf("%s: %w", name, err)}
+		for _, done := range rotated {
+			if err := g.stores[done].RotateKey(g.key); err != nil {
+				errs = append(errs, fmt.Errorf("%s: rollback: %w", done, err))
+			}
+		}
+		return newMultiError(errs)
+	}
+	g.key = newKey
+	return nil
+}
+
+// Close 关闭所有配置，有失败时返回 *MultiError
+func (g *Group) Close() error {
+	return g.each(func(m groupMember) error {
+		return m.Close()
+	})
+}
+
+// 并发地对所有配置调用 fn，错误以配置名为前缀合并为 *MultiError
+func (g *Group) each(fn func(groupMember) error) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := g.names()
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(g.stores[name]); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return newMultiError(errs)
+}
+
+// 按名称排序的配置名，保证操作和错误的顺序稳定
+func (g *Group) names() []string {
+	names := make([]string, 0, len(g.stores))
+	for name := range g.stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 保存缓存中的配置，没有缓存时不做任何操作。保存后缓存失效，下一次读取时重新读取文件。
+func (cs *ConfigStore[T]) saveCached() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if !cs.hasCached {
+		return nil
+	}
+	return cs.wrapError("save", cs.saveConfig(context.Background(), cs.cached, false))
+}
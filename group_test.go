@@ -0,0 +1,143 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type flagsConfig struct {
+	Beta bool `json:"beta"`
+}
+
+func TestGroup(t *testing.T) {
+	dir := t.TempDir()
+	g := NewGroup("0123456789abcdef")
+	main, err := Add[myConfig](g, "main", filepath.Join(dir, "main.data"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	flags, err := Add[flagsConfig](g, "flags", filepath.Join(dir, "flags.data"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := Add[myConfig](g, "main", filepath.Join(dir, "other.data")); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a duplicate name, but got: %v", err)
+	}
+
+	// 还没有保存过配置时 LoadAll 不返回错误
+	if err := g.LoadAll(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := main.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := flags.SaveConfig(flagsConfig{Beta: true}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := g.LoadAll(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !main.IsCached() || !flags.IsCached() {
+		t.Errorf("Expected all stores to be cached after LoadAll")
+	}
+	if err := g.SaveAll(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 轮换后所有配置都使用新 key
+	newKey := "fedcba9876543210"
+	if err := g.RotateKey(newKey); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	reopened, err := NewConfigStore[flagsConfig](filepath.Join(dir, "flags.data"), newKey)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config, err := reopened.LoadConfig(); err != nil || !config.Beta {
+		t.Errorf("Expected flags to be readable with the new key, but got: %+v, %v", config, err)
+	}
+	if config, err := main.LoadConfig(); err != nil || config.Username != "testuser" {
+		t.Errorf("Expected main config to be readable, but got: %+v, %v", config, err)
+	}
+}
+
+func TestGroupRotateKeyRollback(t *testing.T) {
+	dir := t.TempDir()
+	g := NewGroup("0123456789abcdef")
+	a, err := Add[myConfig](g, "a", filepath.Join(dir, "a.data"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	b, err := Add[myConfig](g, "b", filepath.Join(dir, "b.data"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := a.SaveConfig(myConfig{Username: "a"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// b 的文件内容损坏，轮换失败
+	if err := os.WriteFile(filepath.Join(dir, "b.data"), []byte("corrupt data that cannot be decrypted"), 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	err = g.RotateKey("fedcba9876543210")
+	if err == nil || !strings.Contains(err.Error(), "b: ") {
+		t.Fatalf("Expected an error for store b, but got: %v", err)
+	}
+
+	// a 被轮换回原来的 key
+	reopened, err := NewConfigStore[myConfig](filepath.Join(dir, "a.data"), "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config, err := reopened.LoadConfig(); err != nil || config.Username != "a" {
+		t.Errorf("Expected a to use the old key after rollback, but got: %+v, %v", config, err)
+	}
+	if _, err := b.LoadConfig(); err == nil {
+		t.Errorf("Expected b to remain unreadable")
+	}
+}
+
+func TestGroupMultiError(t *testing.T) {
+	dir := t.TempDir()
+	g := NewGroup("0123456789abcdef")
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		cs, err := Add[myConfig](g, name, filepath.Join(dir, name+".data"))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	if err := g.LoadAll(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 5 个配置中的 2 个损坏
+	for _, name := range []string{"b", "d"} {
+		if err := os.WriteFile(filepath.Join(dir, name+".data"), []byte("corrupt data that cannot be decrypted"), 0600); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	err := g.LoadAll()
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("Expected a *MultiError, but got: %T %v", err, err)
+	}
+	if len(*multi) != 2 || !strings.HasPrefix((*multi)[0].Error(), "b: ") || !strings.HasPrefix((*multi)[1].Error(), "d: ") {
+		t.Errorf("Expected errors for b and d, but got: %v", *multi)
+	}
+	if !errors.Is(err, ErrDecryptionFailed) && !errors.Is(err, ErrCorruptData) {
+		t.Errorf("Expected errors.Is to see the wrapped errors, but got: %v", err)
+	}
+	if err.Error() != (*multi)[0].Error()+"\n"+(*multi)[1].Error() {
+		t.Errorf("Expected one error per line, but got: %q", err.Error())
+	}
+
+	// 没有失败时返回 nil 而不是空的 *MultiError
+	if err := g.Close(); err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package configstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// 当前写入的文件格式版本，没有头部的旧文件视为版本 0
+	formatVersion = 1
+	// 文件头部长度
+	headerSize = 8
+)
+
+// 文件头部的魔数 "CSTR"
+var headerMagic = []byte{0x43, 0x53, 0x54, 0x52}
+
+// 文件头部的标志位
+const (
+	// 头部之后紧跟派生参数
+	flagKDF byte = 1 << iota
+	// 派生参数之后紧跟 HMAC 标签
+	flagIntegrity
+	// 明文在加密前经过 gzip 压缩
+	flagGzip
+	// 明文在加密前经过 zstd 压缩
+	flagZstd
+	// 头部之后紧跟 4 字节的数据版本，由 WithMigration 设置
+	flagDataVersion
+	// 数据版本之后紧跟 8 字节的保存时间（Unix 纳秒），由 WithMaxAge 设置
+	flagSavedAt
+)
+
+// fileHeader 是文件开头的明文头部：[魔数 4 字节][版本][标志位][加密模式][序列化格式]，
+// 设置了 flagDataVersion 和 flagSavedAt 时后面依次是大端序的数据版本和保存时间
+type fileHeader struct {
+	version     byte
+	flags       byte
+	cipherMode  CipherMode
+	format      SerializationFormat
+	dataVersion uint32
+	savedAt     int64
+}
+
+func (h fileHeader) marshal() []byte {
+	buf := make([]byte, 0, headerSize+12)
+	buf = append(buf, headerMagic...)
+	buf = append(buf, h.version, h.flags, byte(h.cipherMode), byte(h.format))
+	if h.has(flagDataVersion) {
+		buf = binary.BigEndian.AppendUint32(buf, h.dataVersion)
+	}
+	if h.has(flagSavedAt) {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(h.savedAt))
+	}
+	return buf
+}
+
+// 头部记录的保存时间，没有记录时为零值
+func (h fileHeader) savedTime() time.Time {
+	if !h.has(flagSavedAt) {
+		return time.Time{}
+	}
+	return time.Unix(0, h.savedAt)
+}
+
+func (h fileHeader) has(flag byte) bool {
+	return h.flags&flag != 0
+}
+
+// 解析文件头部，返回头部和剩余数据。没有魔数的文件视为版本 0，不消耗任何数据。
+// 版本 0 文件以随机 IV 开头，与魔数碰撞的概率可以忽略。
+func parseFileHeader(data []byte) (fileHeader, []byte, error) {
+	if len(data) < headerSize || !bytes.Equal(data[:len(headerMagic)], headerMagic) {
+		return fileHeader{}, data, nil
+	}
+	h := fileHeader{
+		version:    data[4],
+		flags:      data[5],
+		cipherMode: CipherMode(data[6]),
+		format:     SerializationFormat(data[7]),
+	}
+	if h.version == 0 || h.version > formatVersion {
+		return fileHeader{}, nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.version)
+	}
+	data = data[headerSize:]
+	if h.has(flagDataVersion) {
+		if len(data) < 4 {
+			return fileHeader{}, nil, fmt.Errorf("%w: invalid data version", ErrCorruptData)
+		}
+		h.dataVersion = binary.BigEndian.Uint32(data)
+		data = data[4:]
+	}
+	if h.has(flagSavedAt) {
+		if len(data) < 8 {
+			return fileHeader{}, nil, fmt.Errorf("%w: invalid saved time", ErrCorruptData)
+		}
+		h.savedAt = int64(binary.BigEndian.Uint64(data))
+		data = data[8:]
+	}
+	return h, data, nil
+}
+
+// FileCipherMode 返回 filename 的文件头部记录的加密模式，不需要 key，
+// 用于在不知道保存时使用的 Option 时选择 WithCipherMode。没有头部的旧文件返回默认的 CipherModeCBC。
+func FileCipherMode(filename string) (CipherMode, error) {
+	data, err := readFile(filename)
+	if os.IsNotExist(err) {
+		err = ErrFileNotFound
+	}
+	if err != nil {
+		return 0, newStoreError("header", filename, err)
+	}
+	header, _, err := parseFileHeader(data)
+	if err != nil {
+		return 0, newStoreError("header", filename, err)
+	}
+	if header.version == 0 {
+		return defaultStoreConfig().cipherMode, nil
+	}
+	return header.cipherMode, nil
+}
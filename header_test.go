@@ -0,0 +1,169 @@
+package configstore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHeaderWritten(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "header.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data, _ := os.ReadFile(filename)
+	expected := []byte{0x43, 0x53, 0x54, 0x52, formatVersion, 0x00, 0x00, 0x00}
+	if !bytes.Equal(data[:headerSize], expected) {
+		t.Errorf("Expected header to be %v, but got: %v", expected, data[:headerSize])
+	}
+}
+
+func TestReadVersion0File(t *testing.T) {
+	// 手动写入一个没有头部的旧格式文件：[IV][密文]
+	filename := filepath.Join(t.TempDir(), "v0.data")
+	key := "0123456789abcdef"
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	plaintext, _ := json.Marshal(config)
+	data, err := sealData(CipherModeCBC, plaintext, []byte(key), rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	cs, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+
+	// 再次保存后升级为当前版本
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data, _ = os.ReadFile(filename)
+	header, _, err := parseFileHeader(data)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if header.version != formatVersion {
+		t.Errorf("Expected version to be %d, but got: %d", formatVersion, header.version)
+	}
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "future.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	data, _ := os.ReadFile(filename)
+	data[4] = formatVersion + 1
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfigOrDefault(myConfig{}); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion, but got: %v", err)
+	}
+}
+
+func TestHeaderCipherMode(t *testing.T) {
+	// 文件头部记录的加密模式必须与当前配置一致
+	filename := filepath.Join(t.TempDir(), "gcm.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key, WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	cs2, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs2.LoadConfigOrDefault(myConfig{}); !errors.Is(err, ErrStoreMismatch) {
+		t.Errorf("Expected ErrStoreMismatch, but got: %v", err)
+	}
+}
+
+func TestHeaderCipherModeForged(t *testing.T) {
+	// 头部声明 CipherModeNone 的明文文件不能绕过 GCM 的认证
+	filename := filepath.Join(t.TempDir(), "forged.data")
+	key := "0123456789abcdef"
+	forger, err := NewConfigStore[myConfig](filename, key, WithCipherMode(CipherModeNone))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := forger.SaveConfig(myConfig{Username: "evil", Password: "x"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	cs, err := NewConfigStore[myConfig](filename, key, WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := cs.LoadConfig()
+	if !errors.Is(err, ErrStoreMismatch) {
+		t.Errorf("Expected ErrStoreMismatch, but got: %+v, %v", config, err)
+	}
+}
+
+func TestIntegrityTagRequired(t *testing.T) {
+	// 启用完整性校验的 ConfigStore 不接受没有标签的文件
+	filename := filepath.Join(t.TempDir(), "plain.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	cs2, err := NewConfigStore[myConfig](filename, key, WithIntegrity())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs2.LoadConfigOrDefault(myConfig{}); !errors.Is(err, ErrIntegrityFailure) {
+		t.Errorf("Expected ErrIntegrityFailure, but got: %v", err)
+	}
+}
+
+func TestFileCipherMode(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "gcm.data")
+	if _, err := FileCipherMode(filename); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound, but got: %v", err)
+	}
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if mode, err := FileCipherMode(filename); err != nil || mode != CipherModeGCM {
+		t.Errorf("Expected AES-GCM, but got: %v, %v", mode, err)
+	}
+}
@@ -0,0 +1,87 @@
+package configstore
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HealthCheck 写入的探测数据，包含写入时间，便于发现过期的检查结果
+type healthSentinel struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Nonce     string    `json:"nonce"`
+}
+
+// HealthCheck 检查 ConfigStore 能否正常读写，可以用于存活探针：
+// 使用当前的 key 和加密设置将一个包含当前时间的探测数据写入配置文件所在目录的临时文件，
+// 读取、解密并比较后删除临时文件，不会读写配置文件本身，也不获取文件锁。
+// 每一步的错误都包装为 *StoreError，Op 为 "health save"、"health load"、"health verify" 或 "health delete"，
+// File 为临时文件的路径。只对 FileBackend 生效，其他存储后端返回 ErrUnsupported。
+func (cs *ConfigStore[T]) HealthCheck(ctx context.Context) error {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	if cs.closed {
+		return cs.wrapError("health", ErrStoreClosed)
+	}
+	fb, ok := cs.fileBackend()
+	if !ok {
+		return cs.wrapError("health", fmt.Errorf("%w: health check is only supported by FileBackend", ErrUnsupported))
+	}
+	f, err := os.CreateTemp(filepath.Dir(fb.filename), filepath.Base(fb.filename)+".health-*")
+	if err != nil {
+		return cs.wrapError("health save", err)
+	}
+	name := f.Name()
+	f.Close()
+
+	err = cs.healthCheck(ctx, name)
+	if removeErr := os.Remove(name); err == nil && removeErr != nil {
+		err = newStoreError("health delete", name, removeErr)
+	}
+	return err
+}
+
+func (cs *ConfigStore[T]) healthCheck(ctx context.Context, name string) error {
+	nonce := make([]byte, 16)
+	if _, err := io.ReadFull(cs.random, nonce); err != nil {
+		return newStoreError("health save", name, err)
+	}
+	sentinel := healthSentinel{CheckedAt: time.Now().UTC(), Nonce: hex.EncodeToString(nonce)}
+	plaintext, err := json.Marshal(sentinel)
+	if err != nil {
+		return newStoreError("health save", name, err)
+	}
+	secret, err := cs.secret(ctx)
+	if err != nil {
+		return newStoreError("health save", name, err)
+	}
+	data, err := cs.seal(secret, plaintext, 0, time.Time{})
+	if err == nil {
+		err = writeFile(name, data, cs.fileMode, cs.sync)
+	}
+	if err != nil {
+		return newStoreError("health save", name, err)
+	}
+
+	data, err = readFile(name)
+	if err != nil {
+		return newStoreError("health load", name, err)
+	}
+	if plaintext, _, err = cs.open(secret, data); err != nil {
+		return newStoreError("health load", name, err)
+	}
+	var loaded healthSentinel
+	if err := json.Unmarshal(plaintext, &loaded); err != nil {
+		return newStoreError("health verify", name, fmt.Errorf("%w: %w", ErrCorruptData, err))
+	}
+	if !loaded.CheckedAt.Equal(sentinel.CheckedAt) || loaded.Nonce != sentinel.Nonce {
+		return newStoreError("health verify", name, fmt.Errorf("%w: health check data does not match", ErrCorruptData))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package configstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHealthCheck(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "health.data")
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithCipherMode(CipherModeGCM), WithIntegrity())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	before, _ := os.ReadFile(filename)
+
+	if err := cs.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 不修改配置文件，不留下临时文件
+	if after, _ := os.ReadFile(filename); !bytes.Equal(after, before) {
+		t.Error("Expected config file to be unchanged")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the config file, but got: %v", entries)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Errorf("Expected no error after health check, but got: %v", err)
+	}
+}
+
+func TestHealthCheckFailure(t *testing.T) {
+	dir := t.TempDir()
+	cs, err := New[myConfig](WithFile(filepath.Join(dir, "health.data")), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 目录不存在时无法创建临时文件
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var se *StoreError
+	if err := cs.HealthCheck(context.Background()); !errors.As(err, &se) || se.Op != "health save" {
+		t.Errorf("Expected a health save error, but got: %v", err)
+	}
+
+	memory, err := NewEphemeralStore[myConfig]("0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := memory.HealthCheck(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, but got: %v", err)
+	}
+	memory.Close()
+	if err := cs.Close(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.HealthCheck(context.Background()); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Expected ErrStoreClosed, but got: %v", err)
+	}
+}
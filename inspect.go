@@ -0,0 +1,121 @@
+package configstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Inspect 读取并解密 filename，不需要在编译期知道配置类型，用于在 init 容器或运维工具中输出配置。
+// 先以 # 开头的注释输出文件头部记录的版本、加密模式、序列化格式、派生参数和保存时间，再输出缩进后的 JSON。
+// 文件头部带有派生参数时 key 作为密码使用。非 JSON 格式的配置转换为 JSON 输出，gob、binary 和自定义格式返回 ErrUnsupported。
+// 不会执行迁移、校验或环境变量覆盖，configstore:"encrypt" 字段保持加密后的值。
+func Inspect(filename string, key string, w io.Writer) error {
+	err := inspect(filename, key, w)
+	return newStoreError("inspect", filename, err)
+}
+
+func inspect(filename string, key string, w io.Writer) error {
+	data, err := readFile(filename)
+	if os.IsNotExist(err) {
+		return ErrFileNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return ErrNoConfig
+	}
+	header, rest, err := parseFileHeader(data)
+	if err != nil {
+		return err
+	}
+
+	// 按照文件头部构造 ConfigStore，版本 0 的文件使用默认配置
+	cfg := defaultStoreConfig()
+	cfg.key = []byte(key)
+	var params kdfParams
+	if header.version != 0 {
+		cfg.cipherMode, cfg.format = header.cipherMode, header.format
+		if header.has(flagKDF) {
+			if params, _, err = parseKDFParams(rest); err != nil {
+				return err
+			}
+			cfg.kdf = params.kdf
+		}
+	}
+	if cfg.kdf == KDFNone {
+		if err := cfg.cipherMode.checkKeyLen(len(cfg.key)); err != nil {
+			return err
+		}
+	}
+	cs := &ConfigStore[json.RawMessage]{storeConfig: cfg}
+	plaintext, header, err := cs.open(cfg.key, data)
+	if err != nil {
+		return err
+	}
+	body, err := indentConfig(header.format, plaintext)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# version: %d\n", header.version)
+	fmt.Fprintf(&buf, "# cipher: %v\n", header.cipherMode)
+	fmt.Fprintf(&buf, "# format: %v\n", header.format)
+	if header.has(flagKDF) {
+		fmt.Fprintf(&buf, "# kdf: %s\n", params.describe())
+	}
+	if header.has(flagDataVersion) {
+		fmt.Fprintf(&buf, "# data version: %d\n", header.dataVersion)
+	}
+	if header.has(flagSavedAt) {
+		fmt.Fprintf(&buf, "# saved at: %s\n", header.savedTime().UTC().Format(time.RFC3339))
+	}
+	buf.Write(body)
+	buf.WriteByte('\n')
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// 将解密后的数据转换为缩进的 JSON
+func indentConfig(format SerializationFormat, plaintext []byte) ([]byte, error) {
+	if format == FormatJSON {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, plaintext, "", "  "); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptData, err)
+		}
+		return buf.Bytes(), nil
+	}
+	switch format {
+	case FormatTOML, FormatYAML, FormatMessagePack:
+	default:
+		return nil, fmt.Errorf("%w: cannot inspect %v config", ErrUnsupported, format)
+	}
+	codec, err := format.codec()
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := codec.Unmarshal(plaintext, &v); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptData, err)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// 派生算法和参数，不包含盐
+func (p kdfParams) describe() string {
+	switch p.kdf {
+	case KDFPBKDF2:
+		return fmt.Sprintf("%v (iterations=%d)", p.kdf, p.iterations)
+	case KDFArgon2id:
+		return fmt.Sprintf("%v (time=%d, memory=%d KiB, threads=%d)", p.kdf, p.time, p.memory, p.threads)
+	case KDFScrypt:
+		return fmt.Sprintf("%v (N=%d, r=%d, p=%d)", p.kdf, p.n, p.r, p.p)
+	default:
+		return p.kdf.String()
+	}
+}
@@ -0,0 +1,75 @@
+package configstore
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "inspect.data")
+	cs, err := Open[myConfig](filename, "password", WithPBKDF2Iterations(1000), WithCipherMode(CipherModeGCM), WithMaxAge(time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Inspect(filename, "password", &buf); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	output := buf.String()
+	for _, expected := range []string{
+		"# version: 1\n",
+		"# cipher: AES-GCM\n",
+		"# format: JSON\n",
+		"# kdf: PBKDF2-HMAC-SHA256 (iterations=1000)\n",
+		"# saved at: ",
+		"\n{\n  \"username\": \"testuser\"",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, but got: %s", expected, output)
+		}
+	}
+
+	if err := Inspect(filename, "wrong password", &buf); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed, but got: %v", err)
+	}
+	if err := Inspect(filepath.Join(t.TempDir(), "missing.data"), "password", &buf); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound, but got: %v", err)
+	}
+}
+
+func TestInspectFormats(t *testing.T) {
+	key := "0123456789abcdef"
+	for _, format := range []SerializationFormat{FormatYAML, FormatTOML, FormatMessagePack, FormatGob} {
+		filename := filepath.Join(t.TempDir(), "inspect.data")
+		cs, err := New[myConfig](WithFile(filename), WithKey(key), WithFormat(format))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		var buf bytes.Buffer
+		err = Inspect(filename, key, &buf)
+		if format == FormatGob {
+			if !errors.Is(err, ErrUnsupported) {
+				t.Errorf("Expected ErrUnsupported, but got: %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Expected no error for %v, but got: %v", format, err)
+		}
+		if !strings.Contains(buf.String(), "\"testuser\"") {
+			t.Errorf("Expected %v config as JSON, but got: %s", format, buf.String())
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package configstore
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+const (
+	// HMAC-SHA256 标签长度
+	integrityTagSize = sha256.Size
+	// 通过 HKDF 派生 HMAC key 时使用的 info
+	integrityKeyInfo = "configstore hmac-sha256"
+)
+
+// 由加密 key 派生出独立的 HMAC key，避免同一个 key 同时用于加密和认证
+func deriveHMACKey(key []byte) ([]byte, error) {
+	return hkdf.Key(sha256.New, key, nil, integrityKeyInfo, sha256.Size)
+}
+
+// 计算文件头部和密文的 HMAC 标签
+func computeIntegrityTag(key []byte, header []byte, body []byte) ([]byte, error) {
+	hmacKey, err := deriveHMACKey(key)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(header)
+	mac.Write(body)
+	return mac.Sum(nil), nil
+}
+
+// 校验 HMAC 标签，不匹配时返回 ErrIntegrityFailure
+func verifyIntegrityTag(key []byte, header []byte, tag []byte, body []byte) error {
+	expected, err := computeIntegrityTag(key, header, body)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(tag, expected) {
+		return ErrIntegrityFailure
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIntegritySaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "integrity.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key, WithIntegrity())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestIntegrityTamperedFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "integrity.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key, WithIntegrity())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	original, _ := os.ReadFile(filename)
+
+	// 分别篡改 HMAC 标签、IV 和密文
+	for _, offset := range []int{0, integrityTagSize, len(original) - 1} {
+		data := append([]byte(nil), original...)
+		data[offset] ^= 0x01
+		if err := os.WriteFile(filename, data, 0644); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		_, err := cs.LoadConfigOrDefault(myConfig{})
+		if !errors.Is(err, ErrIntegrityFailure) {
+			t.Errorf("Expected ErrIntegrityFailure at offset %d, but got: %v", offset, err)
+		}
+	}
+}
+
+func TestIntegrityWithPassword(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "integrity.data")
+	cs, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithPBKDF2Iterations(1000), WithIntegrity())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 派生参数同样受 HMAC 保护：修改盐的最后一个字节
+	data, _ := os.ReadFile(filename)
+	_, rest, err := parseKDFParams(data[headerSize:])
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data[len(data)-len(rest)-1] ^= 0x01
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfigOrDefault(myConfig{}); !errors.Is(err, ErrIntegrityFailure) {
+		t.Errorf("Expected ErrIntegrityFailure, but got: %v", err)
+	}
+}
+
+func TestIntegrityOptional(t *testing.T) {
+	// 未启用 WithIntegrity 时写入的文件仍可在未启用的 ConfigStore 中读取
+	filename := filepath.Join(t.TempDir(), "plain.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
@@ -0,0 +1,296 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: internal/testpb/config.proto
+
+package testpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Config struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Host           string                 `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	Port           int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Tls            bool                   `protobuf:"varint,4,opt,name=tls,proto3" json:"tls,omitempty"`
+	Username       string                 `protobuf:"bytes,5,opt,name=username,proto3" json:"username,omitempty"`
+	Password       string                 `protobuf:"bytes,6,opt,name=password,proto3" json:"password,omitempty"`
+	TimeoutMs      int64                  `protobuf:"varint,7,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	MaxConnections int32                  `protobuf:"varint,8,opt,name=max_connections,json=maxConnections,proto3" json:"max_connections,omitempty"`
+	MaxIdle        int32                  `protobuf:"varint,9,opt,name=max_idle,json=maxIdle,proto3" json:"max_idle,omitempty"`
+	SampleRate     float64                `protobuf:"fixed64,10,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	Debug          bool                   `protobuf:"varint,11,opt,name=debug,proto3" json:"debug,omitempty"`
+	LogLevel       string                 `protobuf:"bytes,12,opt,name=log_level,json=logLevel,proto3" json:"log_level,omitempty"`
+	Region         string                 `protobuf:"bytes,13,opt,name=region,proto3" json:"region,omitempty"`
+	Zone           string                 `protobuf:"bytes,14,opt,name=zone,proto3" json:"zone,omitempty"`
+	Tags           []string               `protobuf:"bytes,15,rep,name=tags,proto3" json:"tags,omitempty"`
+	Peers          []string               `protobuf:"bytes,16,rep,name=peers,proto3" json:"peers,omitempty"`
+	Retries        uint32                 `protobuf:"varint,17,opt,name=retries,proto3" json:"retries,omitempty"`
+	Version        uint64                 `protobuf:"varint,18,opt,name=version,proto3" json:"version,omitempty"`
+	Weight         float32                `protobuf:"fixed32,19,opt,name=weight,proto3" json:"weight,omitempty"`
+	Token          []byte                 `protobuf:"bytes,20,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_internal_testpb_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_testpb_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_internal_testpb_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Config) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Config) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Config) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Config) GetTls() bool {
+	if x != nil {
+		return x.Tls
+	}
+	return false
+}
+
+func (x *Config) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Config) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Config) GetTimeoutMs() int64 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+func (x *Config) GetMaxConnections() int32 {
+	if x != nil {
+		return x.MaxConnections
+	}
+	return 0
+}
+
+func (x *Config) GetMaxIdle() int32 {
+	if x != nil {
+		return x.MaxIdle
+	}
+	return 0
+}
+
+func (x *Config) GetSampleRate() float64 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *Config) GetDebug() bool {
+	if x != nil {
+		return x.Debug
+	}
+	return false
+}
+
+func (x *Config) GetLogLevel() string {
+	if x != nil {
+		return x.LogLevel
+	}
+	return ""
+}
+
+func (x *Config) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Config) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *Config) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Config) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+func (x *Config) GetRetries() uint32 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *Config) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Config) GetWeight() float32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Config) GetToken() []byte {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+var File_internal_testpb_config_proto protoreflect.FileDescriptor
+
+const file_internal_testpb_config_proto_rawDesc = "" +
+	"\n" +
+	"\x1cinternal/testpb/config.proto\x12\x12configstore.testpb\"\xfd\x03\n" +
+	"\x06Config\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04host\x18\x02 \x01(\tR\x04host\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\x05R\x04port\x12\x10\n" +
+	"\x03tls\x18\x04 \x01(\bR\x03tls\x12\x1a\n" +
+	"\busername\x18\x05 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x06 \x01(\tR\bpassword\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\a \x01(\x03R\ttimeoutMs\x12'\n" +
+	"\x0fmax_connections\x18\b \x01(\x05R\x0emaxConnections\x12\x19\n" +
+	"\bmax_idle\x18\t \x01(\x05R\amaxIdle\x12\x1f\n" +
+	"\vsample_rate\x18\n" +
+	" \x01(\x01R\n" +
+	"sampleRate\x12\x14\n" +
+	"\x05debug\x18\v \x01(\bR\x05debug\x12\x1b\n" +
+	"\tlog_level\x18\f \x01(\tR\blogLevel\x12\x16\n" +
+	"\x06region\x18\r \x01(\tR\x06region\x12\x12\n" +
+	"\x04zone\x18\x0e \x01(\tR\x04zone\x12\x12\n" +
+	"\x04tags\x18\x0f \x03(\tR\x04tags\x12\x14\n" +
+	"\x05peers\x18\x10 \x03(\tR\x05peers\x12\x18\n" +
+	"\aretries\x18\x11 \x01(\rR\aretries\x12\x18\n" +
+	"\aversion\x18\x12 \x01(\x04R\aversion\x12\x16\n" +
+	"\x06weight\x18\x13 \x01(\x02R\x06weight\x12\x14\n" +
+	"\x05token\x18\x14 \x01(\fR\x05tokenB3Z1github.com/JanusHuang/configstore/internal/testpbb\x06proto3"
+
+var (
+	file_internal_testpb_config_proto_rawDescOnce sync.Once
+	file_internal_testpb_config_proto_rawDescData []byte
+)
+
+func file_internal_testpb_config_proto_rawDescGZIP() []byte {
+	file_internal_testpb_config_proto_rawDescOnce.Do(func() {
+		file_internal_testpb_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_testpb_config_proto_rawDesc), len(file_internal_testpb_config_proto_rawDesc)))
+	})
+	return file_internal_testpb_config_proto_rawDescData
+}
+
+var file_internal_testpb_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_internal_testpb_config_proto_goTypes = []any{
+	(*Config)(nil), // 0: configstore.testpb.Config
+}
+var file_internal_testpb_config_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_internal_testpb_config_proto_init() }
+func file_internal_testpb_config_proto_init() {
+	if File_internal_testpb_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_testpb_config_proto_rawDesc), len(file_internal_testpb_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_internal_testpb_config_proto_goTypes,
+		DependencyIndexes: file_internal_testpb_config_proto_depIdxs,
+		MessageInfos:      file_internal_testpb_config_proto_msgTypes,
+	}.Build()
+	File_internal_testpb_config_proto = out.File
+	file_internal_testpb_config_proto_goTypes = nil
+	file_internal_testpb_config_proto_depIdxs = nil
+}
@@ -0,0 +1,191 @@
+package configstore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWSBackend 将底层 Backend 中的数据保存为 JWS compact 格式的 token，读取时校验签名后返回 payload，
+// 写入时签名生成新的 token。ConfigStore 的加密仍然作用于 payload，同时提供真实性和机密性：
+//
+//	backend, err := configstore.NewJWSBackend(configstore.NewFileBackend("config.jws"), hmacKey)
+//	cs, err := configstore.New[Config](configstore.WithBackend(backend), configstore.WithKey(key))
+//
+// key 为 []byte 时使用 HS256；为 *ecdsa.PrivateKey 或 *ecdsa.PublicKey 时根据曲线使用 ES256、ES384 或 ES512，
+// 只有公钥时只能读取，Write 返回 ErrUnsupported。token 头部的 alg 必须与 key 对应的算法一致。
+type JWSBackend struct {
+	backend Backend
+	alg     string
+	hash    crypto.Hash
+	hmacKey []byte
+	private *ecdsa.PrivateKey
+	public  *ecdsa.PublicKey
+}
+
+// JWS 头部
+type jwsHeader struct {
+	Alg string `json:"alg"`
+}
+
+// NewJWSBackend 创建一个在 backend 中保存 JWS token 的 JWSBackend，key 不是支持的类型时返回 ErrInvalidOption
+func NewJWSBackend(backend Backend, key any) (*JWSBackend, error) {
+	b := &JWSBackend{backend: backend}
+	switch k := key.(type) {
+	case []byte:
+		if len(k) == 0 {
+			return nil, fmt.Errorf("%w: empty HMAC key", ErrInvalidOption)
+		}
+		b.alg, b.hash, b.hmacKey = "HS256", crypto.SHA256, bytes.Clone(k)
+	case *ecdsa.PrivateKey:
+		b.private, b.public = k, &k.PublicKey
+	case *ecdsa.PublicKey:
+		b.public = k
+	default:
+		return nil, fmt.Errorf("%w: unsupported JWS key type %T", ErrInvalidOption, key)
+	}
+	if b.public != nil {
+		switch b.public.Curve {
+		case elliptic.P256():
+			b.alg, b.hash = "ES256", crypto.SHA256
+		case elliptic.P384():
+			b.alg, b.hash = "ES384", crypto.SHA384
+		case elliptic.P521():
+			b.alg, b.hash = "ES512", crypto.SHA512
+		default:
+			return nil, fmt.Errorf("%w: unsupported ECDSA curve", ErrInvalidOption)
+		}
+	}
+	return b, nil
+}
+
+func (b *JWSBackend) Read() ([]byte, error) {
+	token, err := b.backend.Read()
+	if err != nil || len(token) == 0 {
+		return nil, err
+	}
+	token = bytes.TrimSpace(token)
+	parts := bytes.Split(token, []byte("."))
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: invalid JWS compact serialization", ErrCorruptData)
+	}
+	var header jwsHeader
+	if err := decodeJWSPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	// 只接受 key 对应的算法，避免 alg 为 none 或被替换为其他算法
+	if header.Alg != b.alg {
+		return nil, fmt.Errorf("%w: unexpected JWS algorithm %q, want %q", ErrIntegrityFailure, header.Alg, b.alg)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(string(parts[1]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWS payload: %w", ErrCorruptData, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(string(parts[2]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWS signature: %w", ErrCorruptData, err)
+	}
+	signingInput := token[:len(parts[0])+1+len(parts[1])]
+	if !b.verify(signingInput, signature) {
+		return nil, fmt.Errorf("%w: JWS signature verification failed", ErrIntegrityFailure)
+	}
+	return payload, nil
+}
+
+func (b *JWSBackend) Write(data []byte) error {
+	if b.hmacKey == nil && b.private == nil {
+		return fmt.Errorf("%w: JWS backend has no signing key", ErrUnsupported)
+	}
+	header, err := json.Marshal(jwsHeader{Alg: b.alg})
+	if err != nil {
+		return err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(data)
+	signature, err := b.sign([]byte(signingInput))
+	if err != nil {
+		return err
+	}
+	return b.backend.Write([]byte(signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)))
+}
+
+// Delete 删除底层 Backend 中的数据，底层 Backend 没有实现 Deleter 时返回 ErrUnsupported
+func (b *JWSBackend) Delete() error {
+	deleter, ok := b.backend.(Deleter)
+	if !ok {
+		return fmt.Errorf("%w: backend does not support delete", ErrUnsupported)
+	}
+	return deleter.Delete()
+}
+
+func (b *JWSBackend) digest(data []byte) []byte {
+	switch b.hash {
+	case crypto.SHA384:
+		sum := sha512.Sum384(data)
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(data)
+		return sum[:]
+	default:
+		sum := sha256.Sum256(data)
+		return sum[:]
+	}
+}
+
+func (b *JWSBackend) sign(signingInput []byte) ([]byte, error) {
+	if b.hmacKey != nil {
+		mac := hmac.New(sha256.New, b.hmacKey)
+		mac.Write(signingInput)
+		return mac.Sum(nil), nil
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, b.private, b.digest(signingInput))
+	if err != nil {
+		return nil, err
+	}
+	// JWS 使用定长的 R || S 表示 ECDSA 签名
+	size := b.curveSize()
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+	return signature, nil
+}
+
+func (b *JWSBackend) verify(signingInput, signature []byte) bool {
+	if b.hmacKey != nil {
+		mac := hmac.New(sha256.New, b.hmacKey)
+		mac.Write(signingInput)
+		return hmac.Equal(signature, mac.Sum(nil))
+	}
+	size := b.curveSize()
+	if len(signature) != 2*size {
+		return false
+	}
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+	return ecdsa.Verify(b.public, b.digest(signingInput), r, s)
+}
+
+// ECDSA 签名中 R 和 S 各自的字节数
+func (b *JWSBackend) curveSize() int {
+	return (b.public.Curve.Params().BitSize + 7) / 8
+}
+
+// 解码 base64url 编码的 JSON
+func decodeJWSPart(part []byte, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(string(part))
+	if err != nil {
+		return fmt.Errorf("%w: invalid JWS header: %w", ErrCorruptData, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: invalid JWS header: %w", ErrCorruptData, err)
+	}
+	return nil
+}
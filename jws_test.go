@@ -0,0 +1,125 @@
+package configstore
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestJWSBackendRFC7515(t *testing.T) {
+	// RFC 7515 附录 A.1 中的 HS256 示例
+	key, _ := base64.RawURLEncoding.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	inner := NewMemoryBackend()
+	inner.Write([]byte(token + "\n"))
+	b, err := NewJWSBackend(inner, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	payload, err := b.Read()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !bytes.HasPrefix(payload, []byte(`{"iss":"joe"`)) {
+		t.Errorf("Expected the example payload, but got: %q", payload)
+	}
+}
+
+func TestJWSBackend(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for name, key := range map[string]any{
+		"HS256": []byte("0123456789abcdef0123456789abcdef"),
+		"ES384": ecKey,
+	} {
+		filename := filepath.Join(t.TempDir(), "config.jws")
+		backend, err := NewJWSBackend(NewFileBackend(filename), key)
+		if err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", name, err)
+		}
+		cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"))
+		if err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", name, err)
+		}
+		if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+			t.Errorf("%s: expected ErrNoConfig, but got: %v", name, err)
+		}
+		config := myConfig{Username: "testuser", Password: "testpass"}
+		if err := cs.SaveConfig(config); err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", name, err)
+		}
+		if loaded, err := cs.LoadConfig(); err != nil || loaded != config {
+			t.Errorf("%s: expected %+v, but got: %+v, %v", name, config, loaded, err)
+		}
+
+		// 文件中是三段式的 token，头部记录了算法
+		token, _ := NewFileBackend(filename).Read()
+		parts := bytes.Split(token, []byte("."))
+		if len(parts) != 3 {
+			t.Fatalf("%s: expected a compact JWS, but got: %q", name, token)
+		}
+		if header, _ := base64.RawURLEncoding.DecodeString(string(parts[0])); string(header) != `{"alg":"`+name+`"}` {
+			t.Errorf("%s: expected alg %s, but got: %s", name, name, header)
+		}
+
+		// 修改 payload 后签名校验失败
+		parts[1][len(parts[1])/2] ^= 0x01
+		if err := NewFileBackend(filename).Write(bytes.Join(parts, []byte("."))); err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", name, err)
+		}
+		if _, err := cs.LoadConfig(); !errors.Is(err, ErrIntegrityFailure) && !errors.Is(err, ErrCorruptData) {
+			t.Errorf("%s: expected the signature check to fail, but got: %v", name, err)
+		}
+	}
+}
+
+func TestJWSBackendVerifyOnly(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	inner := NewMemoryBackend()
+	signer, err := NewJWSBackend(inner, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := signer.Write([]byte("payload")); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 只有公钥时可以校验，但不能写入
+	verifier, err := NewJWSBackend(inner, &key.PublicKey)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if payload, err := verifier.Read(); err != nil || string(payload) != "payload" {
+		t.Errorf("Expected payload, but got: %q, %v", payload, err)
+	}
+	if err := verifier.Write([]byte("payload")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, but got: %v", err)
+	}
+
+	// 其他 key 签名的 token 以及算法不一致的 token 都会被拒绝
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherBackend, _ := NewJWSBackend(inner, &other.PublicKey)
+	if _, err := otherBackend.Read(); !errors.Is(err, ErrIntegrityFailure) {
+		t.Errorf("Expected ErrIntegrityFailure for another key, but got: %v", err)
+	}
+	hmacBackend, _ := NewJWSBackend(inner, []byte("secret"))
+	if _, err := hmacBackend.Read(); !errors.Is(err, ErrIntegrityFailure) {
+		t.Errorf("Expected ErrIntegrityFailure for a different algorithm, but got: %v", err)
+	}
+
+	if _, err := NewJWSBackend(inner, "secret"); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for an unsupported key type, but got: %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package configstore
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF 表示由密码派生加密 key 的算法
+type KDF uint8
+
+const (
+	// KDFNone 表示直接使用原始 key，不做派生
+	KDFNone KDF = iota
+	// KDFPBKDF2 使用 PBKDF2-HMAC-SHA256 派生 key
+	KDFPBKDF2
+	// KDFArgon2id 使用 Argon2id 派生 key，内存困难，更能抵抗 GPU 暴力破解
+	KDFArgon2id
+	// KDFScrypt 使用 scrypt（RFC 7914）派生 key，内存困难，适合不便使用 Argon2 的环境
+	KDFScrypt
+)
+
+const (
+	// DefaultPBKDF2Iterations 是 PBKDF2 的默认迭代次数
+	DefaultPBKDF2Iterations = 600000
+
+	// Argon2id 的默认参数，参考 RFC 9106 的推荐值
+	DefaultArgon2idTime    = 1
+	DefaultArgon2idMemory  = 64 * 1024 // 单位 KiB
+	DefaultArgon2idThreads = 4
+
+	// scrypt 的默认参数，N=2^15、r=8、p=1
+	DefaultScryptN = 1 << 15
+	DefaultScryptR = 8
+	DefaultScryptP = 1
+
+	// PBKDF2 迭代次数的上限，超过上限的文件头部视为损坏，避免伪造的文件让派生 key 长时间阻塞
+	MaxPBKDF2Iterations = 10000000
+
+	// Argon2id 参数的上限，超过上限的文件头部视为损坏，避免伪造的文件耗尽内存或 CPU
+	MaxArgon2idTime    = 16
+	MaxArgon2idMemory  = 1024 * 1024 // 单位 KiB，即 1 GiB
+	MaxArgon2idThreads = 64
+
+	// scrypt 参数的上限，同时要求内存用量 128*N*r 不超过 MaxScryptMemory
+	MaxScryptN      = 1 << 20
+	MaxScryptR      = 32
+	MaxScryptP      = 16
+	MaxScryptMemory = 1 << 30 // 单位字节，即 1 GiB
+
+	// 派生 key 的长度，32 字节可用于所有加密模式
+	derivedKeySize = 32
+	// 每次保存时随机生成的盐长度
+	kdfSaltSize = 16
+)
+
+func (k KDF) String() string {
+	switch k {
+	case KDFNone:
+		return "none"
+	case KDFPBKDF2:
+		return "PBKDF2-HMAC-SHA256"
+	case KDFArgon2id:
+		return "Argon2id"
+	case KDFScrypt:
+		return "scrypt"
+	default:
+		return "unknown"
+	}
+}
+
+// 派生参数被截断
+var errInvalidKDFHeader = fmt.Errorf("%w: invalid kdf header", ErrCorruptData)
+
+// kdfParams 记录派生 key 所需的参数，序列化后保存在文件头部
+type kdfParams struct {
+	kdf        KDF
+	iterations uint32
+	// Argon2id 参数
+	time    uint32
+	memory  uint32
+	threads uint8
+	// scrypt 参数
+	n, r, p uint32
+	salt    []byte
+}
+
+// 根据当前配置生成一组新的派生参数，盐每次都重新生成
+func newKDFParams(cfg *storeConfig, random io.Reader) (kdfParams, error) {
+	p := kdfParams{kdf: cfg.kdf, salt: make([]byte, kdfSaltSize)}
+	if _, err := io.ReadFull(random, p.salt); err != nil {
+		return kdfParams{}, err
+	}
+	switch cfg.kdf {
+	case KDFPBKDF2:
+		if cfg.pbkdf2Iterations <= 0 || cfg.pbkdf2Iterations > MaxPBKDF2Iterations {
+			return kdfParams{}, fmt.Errorf("%w: invalid pbkdf2 iterations %d: must be between 1 and %d", ErrInvalidOption, cfg.pbkdf2Iterations, MaxPBKDF2Iterations)
+		}
+		p.iterations = uint32(cfg.pbkdf2Iterations)
+	case KDFArgon2id:
+		p.time = cfg.argon2Time
+		p.memory = cfg.argon2Memory
+		p.threads = cfg.argon2Threads
+	case KDFScrypt:
+		if cfg.scryptN <= 0 || cfg.scryptN > math.MaxUint32 || cfg.scryptR <= 0 || cfg.scryptR > math.MaxUint32 || cfg.scryptP <= 0 || cfg.scryptP > math.MaxUint32 {
+			return kdfParams{}, fmt.Errorf("%w: invalid scrypt params: N=%d r=%d p=%d", ErrInvalidOption, cfg.scryptN, cfg.scryptR, cfg.scryptP)
+		}
+		p.n, p.r, p.p = uint32(cfg.scryptN), uint32(cfg.scryptR), uint32(cfg.scryptP)
+	default:
+		return kdfParams{}, fmt.Errorf("%w kdf %d", ErrUnsupported, cfg.kdf)
+	}
+	return p, p.validate(ErrInvalidOption)
+}
+
+// 序列化派生参数：[kdf 1 字节][参数][盐]
+func (p kdfParams) marshal() []byte {
+	buf := []byte{byte(p.kdf)}
+	switch p.kdf {
+	case KDFPBKDF2:
+		buf = binary.BigEndian.AppendUint32(buf, p.iterations)
+	case KDFArgon2id:
+		buf = binary.BigEndian.AppendUint32(buf, p.time)
+		buf = binary.BigEndian.AppendUint32(buf, p.memory)
+		buf = append(buf, p.threads)
+	case KDFScrypt:
+		buf = binary.BigEndian.AppendUint32(buf, p.n)
+		buf = binary.BigEndian.AppendUint32(buf, p.r)
+		buf = binary.BigEndian.AppendUint32(buf, p.p)
+	}
+	return append(buf, p.salt...)
+}
+
+// 从文件数据中解析派生参数，返回剩余的数据
+func parseKDFParams(data []byte) (kdfParams, []byte, error) {
+	if len(data) < 1 {
+		return kdfParams{}, nil, errInvalidKDFHeader
+	}
+	p := kdfParams{kdf: KDF(data[0])}
+	data = data[1:]
+	switch p.kdf {
+	case KDFPBKDF2:
+		if len(data) < 4 {
+			return kdfParams{}, nil, errInvalidKDFHeader
+		}
+		p.iterations = binary.BigEndian.Uint32(data)
+		data = data[4:]
+	case KDFArgon2id:
+		if len(data) < 9 {
+			return kdfParams{}, nil, errInvalidKDFHeader
+		}
+		p.time = binary.BigEndian.Uint32(data)
+		p.memory = binary.BigEndian.Uint32(data[4:])
+		p.threads = data[8]
+		data = data[9:]
+	case KDFScrypt:
+		if len(data) < 12 {
+			return kdfParams{}, nil, errInvalidKDFHeader
+		}
+		p.n = binary.BigEndian.Uint32(data)
+		p.r = binary.BigEndian.Uint32(data[4:])
+		p.p = binary.BigEndian.Uint32(data[8:])
+		data = data[12:]
+	default:
+		return kdfParams{}, nil, fmt.Errorf("%w kdf %d in file header", ErrUnsupported, p.kdf)
+	}
+	if len(data) < kdfSaltSize {
+		return kdfParams{}, nil, errInvalidKDFHeader
+	}
+	p.salt = data[:kdfSaltSize]
+	// 参数来自不可信的文件头部
+	if err := p.validate(ErrCorruptData); err != nil {
+		return kdfParams{}, nil, err
+	}
+	return p, data[kdfSaltSize:], nil
+}
+
+// 校验派生参数是否在合理范围内，不合法时返回包装了 kind 的错误：
+// 配置的参数使用 ErrInvalidOption，从文件头部读取的参数使用 ErrCorruptData
+func (p kdfParams) validate(kind error) error {
+	switch p.kdf {
+	case KDFPBKDF2:
+		if p.iterations == 0 || p.iterations > MaxPBKDF2Iterations {
+			return fmt.Errorf("%w: invalid pbkdf2 iterations %d: must be between 1 and %d", kind, p.iterations, MaxPBKDF2Iterations)
+		}
+	case KDFArgon2id:
+		if p.time == 0 || p.time > MaxArgon2idTime {
+			return fmt.Errorf("%w: invalid argon2id time %d: must be between 1 and %d", kind, p.time, MaxArgon2idTime)
+		}
+		// Argon2 要求内存至少为 8*threads KiB
+		if p.threads == 0 || p.threads > MaxArgon2idThreads || p.memory < 8*uint32(p.threads) || p.memory > MaxArgon2idMemory {
+			return fmt.Errorf("%w: invalid argon2id params: memory=%dKiB threads=%d", kind, p.memory, p.threads)
+		}
+	case KDFScrypt:
+		// N 必须是大于 1 的 2 的幂，r*p 必须小于 2^30
+		if p.n <= 1 || p.n&(p.n-1) != 0 || p.r == 0 || p.p == 0 || uint64(p.r)*uint64(p.p) >= 1<<30 {
+			return fmt.Errorf("%w: invalid scrypt params: N=%d r=%d p=%d", kind, p.n, p.r, p.p)
+		}
+		if p.n > MaxScryptN || p.r > MaxScryptR || p.p > MaxScryptP || 128*uint64(p.n)*uint64(p.r) > MaxScryptMemory {
+			return fmt.Errorf("%w: scrypt params exceed limits: N=%d r=%d p=%d", kind, p.n, p.r, p.p)
+		}
+	default:
+		return fmt.Errorf("%w kdf %d", ErrUnsupported, p.kdf)
+	}
+	return nil
+}
+
+// 由密码和参数派生出加密 key
+func (p kdfParams) deriveKey(password []byte) ([]byte, error) {
+	switch p.kdf {
+	case KDFPBKDF2:
+		return pbkdf2.Key(sha256.New, string(password), p.salt, int(p.iterations), derivedKeySize)
+	case KDFArgon2id:
+		return argon2.IDKey(password, p.salt, p.time, p.memory, p.threads, derivedKeySize), nil
+	case KDFScrypt:
+		return scrypt.Key(password, p.salt, int(p.n), int(p.r), int(p.p), derivedKeySize)
+	default:
+		return nil, fmt.Errorf("%w kdf %d", ErrUnsupported, p.kdf)
+	}
+}
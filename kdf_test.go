@@ -0,0 +1,323 @@
+package configstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPasswordStoreSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "password.data")
+	cs, err := NewConfigStoreFromPassword[myConfig](filename, "correct horse battery staple", WithPBKDF2Iterations(1000))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "open.data")
+	cs, err := Open[myConfig](filename, "password", WithPBKDF2Iterations(1000), WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 与 NewConfigStoreFromPassword 写入的文件兼容
+	cs2, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithPBKDF2Iterations(1000), WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs2.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+
+	if _, err := Open[myConfig](filename, ""); err == nil {
+		t.Errorf("Expected an error for an empty password")
+	}
+}
+
+func TestPasswordStoreDefaultIterations(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "password.data")
+	cs, err := NewConfigStoreFromPassword[myConfig](filename, "password")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if cs.pbkdf2Iterations != DefaultPBKDF2Iterations {
+		t.Errorf("Expected %d iterations, but got: %d", DefaultPBKDF2Iterations, cs.pbkdf2Iterations)
+	}
+}
+
+func TestPasswordStoreSaltRegenerated(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "password.data")
+	cs, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithPBKDF2Iterations(1000))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+
+	// 两次保存应当使用不同的盐
+	var salts [][]byte
+	for i := 0; i < 2; i++ {
+		if err := cs.SaveConfig(config); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		data, _ := os.ReadFile(filename)
+		params, _, err := parseKDFParams(data[headerSize:])
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		salts = append(salts, params.salt)
+	}
+	if bytes.Equal(salts[0], salts[1]) {
+		t.Errorf("Expected salt to change between saves")
+	}
+}
+
+func TestPasswordStoreStoredIterations(t *testing.T) {
+	// 修改默认迭代次数后仍能读取旧文件，因为参数保存在文件头部
+	filename := filepath.Join(t.TempDir(), "password.data")
+	cs, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithPBKDF2Iterations(1000))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	cs2, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithPBKDF2Iterations(2000))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs2.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestPasswordStoreWrongPassword(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "password.data")
+	cs, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithPBKDF2Iterations(1000), WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	cs2, err := NewConfigStoreFromPassword[myConfig](filename, "wrong", WithPBKDF2Iterations(1000), WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs2.LoadConfigOrDefault(myConfig{}); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed for a wrong password, but got: %v", err)
+	}
+}
+
+func TestPasswordStoreEmptyPassword(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "password.data")
+	if _, err := NewConfigStoreFromPassword[myConfig](filename, ""); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected an error for an empty password, but got nil")
+	}
+}
+
+func TestArgon2idSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "argon2.data")
+	cs, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithKDF(KDFArgon2id), WithArgon2idParams(1, 1024, 1))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 参数保存在文件头部
+	data, _ := os.ReadFile(filename)
+	params, _, err := parseKDFParams(data[headerSize:])
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if params.kdf != KDFArgon2id || params.time != 1 || params.memory != 1024 || params.threads != 1 {
+		t.Errorf("Unexpected kdf params in header: %+v", params)
+	}
+
+	// 修改默认参数后仍可以用文件中保存的参数读取
+	cs2, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithKDF(KDFArgon2id), WithArgon2idParams(2, 2048, 2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs2.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestKDFMismatch(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "argon2.data")
+	cs, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithKDF(KDFArgon2id), WithArgon2idParams(1, 1024, 1))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	cs2, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithPBKDF2Iterations(1000))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	_, err = cs2.LoadConfigOrDefault(myConfig{})
+	if !errors.Is(err, ErrStoreMismatch) || !strings.Contains(err.Error(), "kdf mismatch") {
+		t.Errorf("Expected a kdf mismatch error, but got: %v", err)
+	}
+}
+
+func TestArgon2idInvalidParams(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "argon2.data")
+	_, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithKDF(KDFArgon2id), WithArgon2idParams(0, 1024, 1))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected an error for invalid argon2id params, but got nil")
+	}
+}
+
+func TestScryptDeriveKey(t *testing.T) {
+	// 相同的密码和盐派生出相同的 key
+	p := kdfParams{kdf: KDFScrypt, n: 1024, r: 8, p: 1, salt: bytes.Repeat([]byte{1}, kdfSaltSize)}
+	key1, err := p.deriveKey([]byte("password"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	key2, err := p.deriveKey([]byte("password"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !bytes.Equal(key1, key2) || len(key1) != derivedKeySize {
+		t.Errorf("Expected deterministic %d byte keys, but got: %x and %x", derivedKeySize, key1, key2)
+	}
+}
+
+func TestScryptSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "scrypt.data")
+	cs, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithKDF(KDFScrypt), WithScryptParams(1024, 8, 1))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 参数保存在文件头部
+	data, _ := os.ReadFile(filename)
+	params, _, err := parseKDFParams(data[headerSize:])
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if params.kdf != KDFScrypt || params.n != 1024 || params.r != 8 || params.p != 1 {
+		t.Errorf("Unexpected kdf params in header: %+v", params)
+	}
+
+	// 修改默认参数后仍可以用文件中保存的参数读取
+	cs2, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithKDF(KDFScrypt), WithScryptParams(2048, 4, 2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs2.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestScryptInvalidParams(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "scrypt.data")
+	_, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithKDF(KDFScrypt), WithScryptParams(1000, 8, 1))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for N that is not a power of two, but got: %v", err)
+	}
+}
+
+func TestArgon2idHeaderParamsTooLarge(t *testing.T) {
+	// 伪造的文件头部声明超大的参数，读取时应视为损坏，而不是尝试分配内存
+	salt := bytes.Repeat([]byte{1}, kdfSaltSize)
+	tests := []kdfParams{
+		{kdf: KDFArgon2id, time: 1, memory: 1<<32 - 1, threads: 4, salt: salt},
+		{kdf: KDFArgon2id, time: 1<<32 - 1, memory: DefaultArgon2idMemory, threads: 4, salt: salt},
+		{kdf: KDFArgon2id, time: 1, memory: DefaultArgon2idMemory, threads: 255, salt: salt},
+	}
+	for _, p := range tests {
+		if _, _, err := parseKDFParams(p.marshal()); !errors.Is(err, ErrCorruptData) {
+			t.Errorf("Expected ErrCorruptData for argon2id params time=%d memory=%d threads=%d, but got: %v", p.time, p.memory, p.threads, err)
+		}
+	}
+}
+
+func TestArgon2idParamsTooLarge(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "argon2.data")
+	_, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithKDF(KDFArgon2id), WithArgon2idParams(1, MaxArgon2idMemory+1, 1))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for argon2id memory over the limit, but got: %v", err)
+	}
+}
+
+func TestScryptHeaderParamsTooLarge(t *testing.T) {
+	// 伪造的文件头部声明超大的参数，读取时应视为损坏，而不是尝试分配内存
+	salt := bytes.Repeat([]byte{1}, kdfSaltSize)
+	tests := []kdfParams{
+		{kdf: KDFScrypt, n: 1 << 30, r: 8, p: 1, salt: salt},
+		{kdf: KDFScrypt, n: 1 << 10, r: 1 << 20, p: 1, salt: salt},
+		{kdf: KDFScrypt, n: 1 << 10, r: 8, p: 1 << 20, salt: salt},
+		{kdf: KDFScrypt, n: MaxScryptN, r: MaxScryptR, p: 1, salt: salt},
+	}
+	for _, p := range tests {
+		if _, _, err := parseKDFParams(p.marshal()); !errors.Is(err, ErrCorruptData) {
+			t.Errorf("Expected ErrCorruptData for scrypt params N=%d r=%d p=%d, but got: %v", p.n, p.r, p.p, err)
+		}
+	}
+}
+
+func TestPBKDF2HeaderIterationsTooLarge(t *testing.T) {
+	// 伪造的文件头部声明超大的迭代次数，读取时应视为损坏，而不是长时间阻塞
+	p := kdfParams{kdf: KDFPBKDF2, iterations: 1<<32 - 1, salt: bytes.Repeat([]byte{1}, kdfSaltSize)}
+	if _, _, err := parseKDFParams(p.marshal()); !errors.Is(err, ErrCorruptData) {
+		t.Errorf("Expected ErrCorruptData for pbkdf2 iterations %d, but got: %v", p.iterations, err)
+	}
+}
+
+func TestPBKDF2IterationsTooLarge(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "pbkdf2.data")
+	_, err := NewConfigStoreFromPassword[myConfig](filename, "password", WithPBKDF2Iterations(MaxPBKDF2Iterations+1))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for pbkdf2 iterations over the limit, but got: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package configstore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// DefaultKeyFileMode 是 RotateKey 写入 key 文件时使用的默认权限，只有所有者可以读取
+const DefaultKeyFileMode os.FileMode = 0400
+
+// WithKeyFile 在创建 ConfigStore 时从 path 读取 key（密码方式创建时为密码），去除首尾的空白字符，
+// 避免 key 以字符串的形式出现在代码、日志或堆栈中。设置后忽略 WithKey 和构造函数中的 key 参数。
+// 文件不存在或无法读取时创建 ConfigStore 返回错误。RotateKey 会同时更新 key 文件和配置文件。
+func WithKeyFile(path string) Option {
+	return func(c *storeConfig) {
+		c.keyFile = path
+	}
+}
+
+// WithKeyFileMode 设置 RotateKey 写入 key 文件时使用的权限，默认为 DefaultKeyFileMode
+func WithKeyFileMode(mode os.FileMode) Option {
+	return func(c *storeConfig) {
+		c.keyFileMode = mode
+	}
+}
+
+// 设置了 WithKeyFile 时读取 key 文件
+func (c *storeConfig) readKeyFile() error {
+	if c.keyFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.keyFile)
+	if err != nil {
+		return fmt.Errorf("%w: key file: %w", ErrInvalidOption, err)
+	}
+	c.key = bytes.TrimSpace(data)
+	return nil
+}
+
+// RotateKey 成功写入配置文件后更新 key 文件，调用方需要持有写锁
+func (cs *ConfigStore[T]) writeKeyFile(newKey string) error {
+	if cs.keyFile == "" {
+		return nil
+	}
+	return writeFile(cs.keyFile, []byte(newKey), cs.keyFileMode, cs.sync)
+}
@@ -0,0 +1,112 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWithKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("0123456789abcdef\n"), 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	filename := filepath.Join(dir, "config.data")
+	cs, err := New[myConfig](WithFile(filename), WithKeyFile(keyFile))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 文件中的换行被去除
+	plain, err := NewConfigStore[myConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded, err := plain.LoadConfig(); err != nil || loaded != config {
+		t.Errorf("Expected %+v, but got: %+v, %v", config, loaded, err)
+	}
+
+	// RotateKey 同时更新 key 文件和配置文件
+	if err := cs.RotateKey("fedcba9876543210"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if string(data) != "fedcba9876543210" {
+		t.Errorf("Expected key file to contain the new key, but got: %q", data)
+	}
+	if runtime.GOOS != "windows" {
+		if info, _ := os.Stat(keyFile); info.Mode().Perm() != DefaultKeyFileMode {
+			t.Errorf("Expected key file mode %v, but got: %v", DefaultKeyFileMode, info.Mode().Perm())
+		}
+	}
+	reopened, err := New[myConfig](WithFile(filename), WithKeyFile(keyFile))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded, err := reopened.LoadConfig(); err != nil || loaded != config {
+		t.Errorf("Expected %+v, but got: %+v, %v", config, loaded, err)
+	}
+}
+
+func TestWithKeyFileMissing(t *testing.T) {
+	dir := t.TempDir()
+	_, err := New[myConfig](WithFile(filepath.Join(dir, "config.data")), WithKeyFile(filepath.Join(dir, "missing")))
+	if !errors.Is(err, ErrInvalidOption) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected ErrInvalidOption for a missing key file, but got: %v", err)
+	}
+	_, err = Open[myConfig](filepath.Join(dir, "config.data"), "", WithKeyFile(filepath.Join(dir, "missing")))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected an error for a missing key file, but got: %v", err)
+	}
+}
+
+func TestWithKeyFileRotateFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory permissions are not supported on windows")
+	}
+	dir := t.TempDir()
+	keyDir := filepath.Join(dir, "keys")
+	if err := os.Mkdir(keyDir, 0700); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	keyFile := filepath.Join(keyDir, "key")
+	if err := os.WriteFile(keyFile, []byte("0123456789abcdef"), 0400); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	filename := filepath.Join(dir, "config.data")
+	cs, err := New[myConfig](WithFile(filename), WithKeyFile(keyFile))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// key 文件所在目录不可写时轮换失败，配置文件恢复为旧 key 加密的内容
+	if err := os.Chmod(keyDir, 0500); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defer os.Chmod(keyDir, 0700)
+	if os.WriteFile(filepath.Join(keyDir, "probe"), nil, 0600) == nil {
+		t.Skip("directory permissions are not enforced (running as root?)")
+	}
+	if err := cs.RotateKey("fedcba9876543210"); err == nil {
+		t.Fatal("Expected an error when the key file cannot be written")
+	}
+	plain, err := NewConfigStore[myConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded, err := plain.LoadConfig(); err != nil || loaded.Username != "testuser" {
+		t.Errorf("Expected config to be readable with the old key, but got: %+v, %v", loaded, err)
+	}
+}
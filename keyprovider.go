@@ -0,0 +1,87 @@
+package configstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeyProvider 在每次加密或解密前提供 key，可以用来对接 AWS KMS、HashiCorp Vault 等密钥管理服务。
+// 对于密码创建的 ConfigStore，返回的内容作为密码使用。
+type KeyProvider interface {
+	GetKey(ctx context.Context) ([]byte, error)
+}
+
+// StaticKeyProvider 总是返回固定的 key
+type StaticKeyProvider struct {
+	key []byte
+}
+
+// NewStaticKeyProvider 创建一个返回固定 key 的 KeyProvider
+func NewStaticKeyProvider(key []byte) *StaticKeyProvider {
+	return &StaticKeyProvider{key: append([]byte(nil), key...)}
+}
+
+func (p *StaticKeyProvider) GetKey(ctx context.Context) ([]byte, error) {
+	return append([]byte(nil), p.key...), nil
+}
+
+// EnvKeyProvider 从环境变量中读取 key
+type EnvKeyProvider struct {
+	name string
+}
+
+// NewEnvKeyProvider 创建一个从名为 name 的环境变量读取 key 的 KeyProvider
+func NewEnvKeyProvider(name string) *EnvKeyProvider {
+	return &EnvKeyProvider{name: name}
+}
+
+func (p *EnvKeyProvider) GetKey(ctx context.Context) ([]byte, error) {
+	value, ok := os.LookupEnv(p.name)
+	if !ok || value == "" {
+		return nil, fmt.Errorf("%w: environment variable %s is not set", ErrKeyUnavailable, p.name)
+	}
+	return []byte(value), nil
+}
+
+// CachedKeyProvider 缓存另一个 KeyProvider 返回的 key，避免频繁请求密钥管理服务
+type CachedKeyProvider struct {
+	provider KeyProvider
+	ttl      time.Duration
+
+	mu        sync.Mutex
+	key       []byte
+	expiresAt time.Time
+}
+
+// NewCachedKeyProvider 创建一个带缓存的 KeyProvider，缓存的 key 在 ttl 之后过期，ttl <= 0 表示永不过期
+func NewCachedKeyProvider(provider KeyProvider, ttl time.Duration) *CachedKeyProvider {
+	return &CachedKeyProvider{provider: provider, ttl: ttl}
+}
+
+func (p *CachedKeyProvider) GetKey(ctx context.Context) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.key != nil && (p.ttl <= 0 || time.Now().Before(p.expiresAt)) {
+		return append([]byte(nil), p.key...), nil
+	}
+
+	key, err := p.provider.GetKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.key = append([]byte(nil), key...)
+	p.expiresAt = time.Now().Add(p.ttl)
+	return key, nil
+}
+
+// Invalidate 清除缓存的 key，下次调用 GetKey 时会重新获取
+func (p *CachedKeyProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	clear(p.key)
+	p.key = nil
+}
@@ -0,0 +1,114 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 统计 GetKey 调用次数的 KeyProvider
+type countingKeyProvider struct {
+	key   []byte
+	calls int
+}
+
+func (p *countingKeyProvider) GetKey(ctx context.Context) ([]byte, error) {
+	p.calls++
+	return p.key, nil
+}
+
+func TestKeyProviderSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "provider.data")
+	kp := &countingKeyProvider{key: []byte("0123456789abcdef")}
+	// key 参数会被忽略
+	cs, err := NewConfigStore[myConfig](filename, "", WithKeyProvider(kp))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+	if kp.calls != 2 {
+		t.Errorf("Expected GetKey to be called 2 times, but got: %d", kp.calls)
+	}
+
+	// 使用相同 key 的普通 ConfigStore 可以读取
+	cs2, err := NewConfigStore[myConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loadConfig, err = cs2.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loadConfig)
+	}
+}
+
+func TestKeyProviderInvalidKey(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "provider.data")
+	cs, err := NewConfigStore[myConfig](filename, "", WithKeyProvider(NewStaticKeyProvider([]byte("short"))))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{}); !errors.Is(err, ErrInvalidKeyLength) {
+		t.Errorf("Expected ErrInvalidKeyLength, but got: %v", err)
+	}
+}
+
+func TestEnvKeyProvider(t *testing.T) {
+	t.Setenv("CONFIGSTORE_TEST_KEY", "0123456789abcdef")
+	key, err := NewEnvKeyProvider("CONFIGSTORE_TEST_KEY").GetKey(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if string(key) != "0123456789abcdef" {
+		t.Errorf("Expected key to be %s, but got: %s", "0123456789abcdef", key)
+	}
+
+	if _, err := NewEnvKeyProvider("CONFIGSTORE_TEST_MISSING_KEY").GetKey(context.Background()); !errors.Is(err, ErrKeyUnavailable) {
+		t.Errorf("Expected an error for a missing environment variable, but got nil")
+	}
+}
+
+func TestCachedKeyProvider(t *testing.T) {
+	kp := &countingKeyProvider{key: []byte("0123456789abcdef")}
+	cached := NewCachedKeyProvider(kp, time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := cached.GetKey(context.Background()); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	if kp.calls != 1 {
+		t.Errorf("Expected GetKey to be called once, but got: %d", kp.calls)
+	}
+
+	// 失效后重新获取
+	cached.Invalidate()
+	if _, err := cached.GetKey(context.Background()); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if kp.calls != 2 {
+		t.Errorf("Expected GetKey to be called 2 times, but got: %d", kp.calls)
+	}
+
+	// 过期后重新获取
+	expiring := NewCachedKeyProvider(kp, time.Nanosecond)
+	expiring.GetKey(context.Background())
+	time.Sleep(time.Millisecond)
+	expiring.GetKey(context.Background())
+	if kp.calls != 4 {
+		t.Errorf("Expected GetKey to be called 4 times, but got: %d", kp.calls)
+	}
+}
@@ -0,0 +1,64 @@
+package configstore
+
+import "maps"
+
+// KVStore 将 map[string]string 类型的配置作为键值对使用，完整的 map 在第一次读取后缓存在内存中，
+// 读取单个 key 不需要每次都解密整个文件
+type KVStore struct {
+	store *ConfigStore[map[string]string]
+}
+
+// NewKVStore 使用 Option 创建 KVStore，Option 与 New 相同，缓存总是启用
+func NewKVStore(opts ...Option) (*KVStore, error) {
+	store, err := New[map[string]string](append(opts, WithCache())...)
+	if err != nil {
+		return nil, err
+	}
+	return &KVStore{store: store}, nil
+}
+
+// Store 返回底层的 ConfigStore，用于 Watch、Close 等操作
+func (kv *KVStore) Store() *ConfigStore[map[string]string] {
+	return kv.store
+}
+
+// Get 返回 key 对应的值，key 不存在或还没有保存过配置时返回 ErrKeyNotFound
+func (kv *KVStore) Get(key string) (string, error) {
+	values, err := kv.store.LoadConfigOrDefault(nil)
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", kv.store.wrapError("get", ErrKeyNotFound)
+	}
+	return value, nil
+}
+
+// GetMany 只读取一次配置，返回 keys 中存在的 key 对应的值，不存在的 key 不会出现在结果中
+func (kv *KVStore) GetMany(keys []string) (map[string]string, error) {
+	values, err := kv.store.LoadConfigOrDefault(nil)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+// Set 在同一次加锁中读取配置、设置 key 并保存
+func (kv *KVStore) Set(key, value string) error {
+	return kv.store.UpdateConfig(func(current map[string]string) (map[string]string, error) {
+		// 缓存中的 map 与调用方共享，复制后再修改，保存失败时缓存不受影响
+		values := maps.Clone(current)
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[key] = value
+		return values, nil
+	})
+}
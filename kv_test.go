@@ -0,0 +1,55 @@
+package configstore
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestKVStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "kv.data")
+	kv, err := NewKVStore(WithFile(filename), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 还没有保存过配置时 key 不存在
+	if _, err := kv.Get("host"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, but got: %v", err)
+	}
+
+	if err := kv.Set("host", "localhost"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := kv.Set("port", "8080"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	value, err := kv.Get("host")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if value != "localhost" {
+		t.Errorf("Expected localhost, but got: %s", value)
+	}
+	if !kv.Store().IsCached() {
+		t.Errorf("Expected the map to be cached after Get")
+	}
+
+	values, err := kv.GetMany([]string{"host", "port", "missing"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := map[string]string{"host": "localhost", "port": "8080"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v, but got: %v", expected, values)
+	}
+
+	// 写入的内容可以被新的 KVStore 读取
+	kv2, err := NewKVStore(WithFile(filename), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if value, err := kv2.Get("port"); err != nil || value != "8080" {
+		t.Errorf("Expected 8080, but got: %s, %v", value, err)
+	}
+}
@@ -0,0 +1,33 @@
+package configstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// LoadAll 并发地调用每个 ConfigStore 的 LoadConfigOrDefault，按 stores 的顺序返回结果。
+// 部分读取失败时，失败的位置为 LoadConfigOrDefault 返回的配置，错误为包含每个失败的 *MultiError。
+func LoadAll[T any](stores []*ConfigStore[T], defaultConfig T) ([]T, error) {
+	return LoadAllContext(context.Background(), stores, defaultConfig)
+}
+
+// LoadAllContext 与 LoadAll 相同，使用 LoadConfigContext 读取，ctx 被取消时尚未完成的读取立即返回
+func LoadAllContext[T any](ctx context.Context, stores []*ConfigStore[T], defaultConfig T) ([]T, error) {
+	configs := make([]T, len(stores))
+	errs := make([]error, len(stores))
+	var wg sync.WaitGroup
+	for i, cs := range stores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			config, err := cs.LoadConfigContext(ctx, defaultConfig)
+			configs[i] = config
+			if err != nil {
+				errs[i] = fmt.Errorf("store %d: %w", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return configs, newMultiError(errs)
+}
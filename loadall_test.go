@@ -0,0 +1,106 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadAll(t *testing.T) {
+	dir := t.TempDir()
+	key := "0123456789abcdef"
+	var stores []*ConfigStore[myConfig]
+	for i := range 3 {
+		cs, err := New[myConfig](WithFile(filepath.Join(dir, fmt.Sprintf("app%d.data", i))), WithKey(key))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		// 最后一个文件没有保存过配置
+		if i < 2 {
+			if err := cs.SaveConfig(myConfig{Username: fmt.Sprintf("user%d", i)}); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+		}
+		stores = append(stores, cs)
+	}
+
+	configs, err := LoadAll(stores, myConfig{Username: "default"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for i, expected := range []string{"user0", "user1", "default"} {
+		if configs[i].Username != expected {
+			t.Errorf("Expected config %d to be %s, but got: %s", i, expected, configs[i].Username)
+		}
+	}
+
+	// 部分读取失败
+	stores[1].Close()
+	configs, err = LoadAll(stores, myConfig{Username: "default"})
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(*multi) != 1 || !errors.Is(err, ErrStoreClosed) {
+		t.Fatalf("Expected a MultiError with ErrStoreClosed, but got: %v", err)
+	}
+	if configs[0].Username != "user0" || configs[1].Username != "default" {
+		t.Errorf("Expected results in order, but got: %+v", configs)
+	}
+}
+
+func TestLoadAllContext(t *testing.T) {
+	backend := &blockingBackend{release: make(chan struct{})}
+	defer close(backend.release)
+	cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := LoadAllContext(ctx, []*ConfigStore[myConfig]{cs, cs}, myConfig{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, but got: %v", err)
+	}
+}
+
+// 每次读取需要 1ms 的存储后端，模拟网络存储
+type slowBackend struct {
+	MemoryBackend
+}
+
+func (b *slowBackend) Read() ([]byte, error) {
+	time.Sleep(time.Millisecond)
+	return b.MemoryBackend.Read()
+}
+
+// 比较依次读取和使用 LoadAll 并发读取 5 个配置
+func BenchmarkLoadAll(b *testing.B) {
+	var stores []*ConfigStore[myConfig]
+	for range 5 {
+		cs, err := New[myConfig](WithBackend(&slowBackend{}), WithKey("0123456789abcdef"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+			b.Fatal(err)
+		}
+		stores = append(stores, cs)
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, cs := range stores {
+				if _, err := cs.LoadConfigOrDefault(myConfig{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := LoadAll(stores, myConfig{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package configstore
+
+import (
+	"os"
+)
+
+// WithFileLock 在读写配置文件时对同目录下的 filename.lock 加建议锁（Linux/macOS 使用 flock，Windows 使用 LockFileEx），
+// 读取时加共享锁，写入时加排他锁，用于多个进程共享同一个配置文件的场景。仅对 FileBackend 生效。
+func WithFileLock() Option {
+	return func(c *storeConfig) {
+		c.fileLock = true
+	}
+}
+
+// 对配置文件对应的锁文件加锁，返回释放锁的函数。未启用 WithFileLock 或不是 FileBackend 时不加锁。
+// 调用方需要持有 cs.mu，文件锁只负责进程之间的互斥。
+func (cs *ConfigStore[T]) lockFile(exclusive bool) (func(), error) {
+	fb, ok := cs.fileBackend()
+	if !cs.fileLock || !ok {
+		return func() {}, nil
+	}
+	f, err := os.OpenFile(fb.filename+".lock", os.O_RDWR|os.O_CREATE, cs.fileMode)
+	if err != nil {
+		return nil, err
+	}
+	if err := flockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		funlockFile(f)
+		f.Close()
+	}, nil
+}
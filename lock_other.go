@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package configstore
+
+import (
+	"fmt"
+	"os"
+)
+
+func flockFile(f *os.File, exclusive bool) error {
+	return fmt.Errorf("%w: file locking on this platform", ErrUnsupported)
+}
+
+func funlockFile(f *os.File) error {
+	return nil
+}
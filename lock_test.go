@@ -0,0 +1,85 @@
+package configstore
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileLockBlocksWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "lock.data")
+	cs1, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithFileLock())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	cs2, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithFileLock())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 模拟另一个进程持有排他锁，flock 的锁属于打开的文件，同一进程中不同的文件描述符之间同样互斥
+	unlock, err := cs1.lockFile(true)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cs2.SaveConfig(myConfig{Username: "testuser"})
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("Expected SaveConfig to wait for the lock, but it returned: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected SaveConfig to finish after the lock is released")
+	}
+	config, err := cs1.LoadConfig()
+	if err != nil || config.Username != "testuser" {
+		t.Errorf("Expected username testuser, but got: %+v, %v", config, err)
+	}
+}
+
+func TestFileLockConcurrentStores(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "lock.data")
+	stores := make([]*ConfigStore[myConfig], 4)
+	for i := range stores {
+		cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithFileLock(), WithBackup(2))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		stores[i] = cs
+	}
+	if err := stores[0].SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 每个 ConfigStore 有自己的互斥锁，只有文件锁保证它们之间的读写不会交错
+	var wg sync.WaitGroup
+	for _, cs := range stores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				// 其他 ConfigStore 刚写入过文件，SaveConfig 会返回 ErrExternalModification
+				if err := cs.ForceWrite(myConfig{Username: "testuser"}); err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+					return
+				}
+				if _, err := cs.LoadConfig(); err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
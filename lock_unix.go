@@ -0,0 +1,27 @@
+//go:build unix
+
+package configstore
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// 使用 flock 加锁，锁被其他进程持有时阻塞等待
+func flockFile(f *os.File, exclusive bool) error {
+	how := unix.LOCK_SH
+	if exclusive {
+		how = unix.LOCK_EX
+	}
+	for {
+		err := unix.Flock(int(f.Fd()), how)
+		if err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+func funlockFile(f *os.File) error {
+	return unix.Flock(int(f.Fd()), unix.LOCK_UN)
+}
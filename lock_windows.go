@@ -0,0 +1,22 @@
+//go:build windows
+
+package configstore
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// 使用 LockFileEx 锁定文件的第一个字节，锁被其他进程持有时阻塞等待
+func flockFile(f *os.File, exclusive bool) error {
+	var flags uint32
+	if exclusive {
+		flags = windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	return windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, 1, 0, &windows.Overlapped{})
+}
+
+func funlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &windows.Overlapped{})
+}
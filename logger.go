@@ -0,0 +1,39 @@
+package configstore
+
+// Logger 是 ConfigStore 输出日志使用的接口，*slog.Logger 实现了该接口。
+// args 为交替出现的键和值，与 slog 的用法相同。
+// 实现同时提供 Warn(msg string, args ...any) 方法时（例如 *slog.Logger），警告通过 Warn 输出，否则通过 Info 输出。
+type Logger interface {
+	Info(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// 输出警告，Logger 没有 Warn 方法时使用 Info
+func (cs *ConfigStore[T]) warn(msg string, args ...any) {
+	if w, ok := cs.logger.(interface{ Warn(string, ...any) }); ok {
+		w.Warn(msg, args...)
+		return
+	}
+	cs.logger.Info(msg, args...)
+}
+
+// WithLogger 设置输出日志使用的 Logger，目前用于记录 Watch 在后台重新读取配置的结果以及 WithRecoveryMode 的恢复。
+// 默认不输出任何日志。
+func WithLogger(l Logger) Option {
+	return func(c *storeConfig) {
+		if l == nil {
+			l = NewDiscardLogger()
+		}
+		c.logger = l
+	}
+}
+
+// NewDiscardLogger 返回一个丢弃所有日志的 Logger
+func NewDiscardLogger() Logger {
+	return discardLogger{}
+}
+
+type discardLogger struct{}
+
+func (discardLogger) Info(string, ...any)  {}
+func (discardLogger) Error(string, ...any) {}
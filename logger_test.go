@@ -0,0 +1,120 @@
+package configstore
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// *slog.Logger 可以直接作为 Logger 使用
+var _ Logger = (*slog.Logger)(nil)
+
+// 记录日志消息的 Logger
+type recordingLogger struct {
+	mu     sync.Mutex
+	infos  []string
+	warns  []string
+	errors []string
+}
+
+func (l *recordingLogger) Info(msg string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.infos = append(l.infos, msg)
+}
+
+func (l *recordingLogger) Warn(msg string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, msg)
+}
+
+func (l *recordingLogger) Error(msg string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, msg)
+}
+
+func TestWatchLogsReloadErrors(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "watch.data")
+	logger := &recordingLogger{}
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithWatchDebounce(20*time.Millisecond), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	errs := make(chan error, 10)
+	cancel, err := cs.Watch(context.Background(), func(config myConfig, err error) {
+		errs <- err
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defer cancel()
+
+	// 写入无法解密的内容
+	if err := os.WriteFile(filename, []byte("not an encrypted config"), 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("Expected a reload error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for change notification")
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.errors) == 0 || len(logger.infos) != 0 {
+		t.Errorf("Expected only error logs, but got: %v, %v", logger.errors, logger.infos)
+	}
+}
+
+func TestDiscardLogger(t *testing.T) {
+	// 未设置 WithLogger 时使用 NewDiscardLogger
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()), WithLogger(nil))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, ok := cs.logger.(discardLogger); !ok {
+		t.Errorf("Expected discard logger, but got: %T", cs.logger)
+	}
+	NewDiscardLogger().Error("ignored", "key", "value")
+}
+
+// 只实现 Info 和 Error 的 Logger
+type infoErrorLogger struct {
+	infos []string
+}
+
+func (l *infoErrorLogger) Info(msg string, args ...any) { l.infos = append(l.infos, msg) }
+func (l *infoErrorLogger) Error(string, ...any)         {}
+
+func TestLoggerWithoutWarn(t *testing.T) {
+	// 没有 Warn 方法的 Logger 通过 Info 输出警告
+	logger := &infoErrorLogger{}
+	cs, err := NewEphemeralStore[myConfig]("0123456789abcdef", WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	cs.warn("configstore: warning")
+	if len(logger.infos) != 1 || logger.infos[0] != "configstore: warning" {
+		t.Errorf("Expected warning to be logged as info, but got: %v", logger.infos)
+	}
+
+	recording := &recordingLogger{}
+	cs, err = NewEphemeralStore[myConfig]("0123456789abcdef", WithLogger(recording))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	cs.warn("configstore: warning")
+	if len(recording.warns) != 1 || len(recording.infos) != 0 {
+		t.Errorf("Expected warning to use Warn, but got: %v, %v", recording.warns, recording.infos)
+	}
+}
@@ -0,0 +1,87 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Merge 读取当前保存的配置，将 partial 中提供了值的字段深度合并进去后保存。
+// 字段是否提供与 json 的 omitempty 规则一致：false、0、空字符串、nil 指针、空 slice 和空 map 视为未提供；
+// 结构体逐字段合并，map 逐个 key 合并，time.Time 等实现了 json.Marshaler 的类型整体覆盖。需要将字段设置为零值时使用指针字段，非 nil 的指针总是会覆盖原来的值。
+// 还没有保存过配置时从 T 的零值开始合并。
+func (cs *ConfigStore[T]) Merge(partial T) error {
+	cs.mu.Lock()
+	config, _, err := cs.loadConfig(context.Background())
+	if err != nil && !errors.Is(err, ErrNoConfig) {
+		cs.mu.Unlock()
+		return cs.wrapError("merge", err)
+	}
+	dst := reflect.ValueOf(&config).Elem()
+	mergeValue(dst, reflect.ValueOf(partial))
+	err = cs.saveConfig(context.Background(), config, false)
+	cs.mu.Unlock()
+	if err != nil {
+		return cs.wrapError("merge", err)
+	}
+
+	for _, fn := range cs.onSave {
+		fn(config)
+	}
+	return nil
+}
+
+// 将 src 中提供了值的部分合并到 dst，dst 必须是可设置的
+func mergeValue(dst, src reflect.Value) {
+	// time.Time 等自定义了序列化方式的类型作为整体覆盖，它们的字段没有导出，无法逐字段合并
+	if t := src.Type(); t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+		return
+	}
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if !src.Type().Field(i).IsExported() {
+				continue
+			}
+			mergeValue(dst.Field(i), src.Field(i))
+		}
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		// 指向结构体的指针继续深度合并，其他指针直接覆盖
+		if src.Elem().Kind() == reflect.Struct && !dst.IsNil() {
+			merged := reflect.New(src.Elem().Type())
+			merged.Elem().Set(dst.Elem())
+			mergeValue(merged.Elem(), src.Elem())
+			dst.Set(merged)
+			return
+		}
+		dst.Set(src)
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		merged := reflect.MakeMapWithSize(src.Type(), dst.Len()+src.Len())
+		iter := dst.MapRange()
+		for iter.Next() {
+			merged.SetMapIndex(iter.Key(), iter.Value())
+		}
+		iter = src.MapRange()
+		for iter.Next() {
+			merged.SetMapIndex(iter.Key(), iter.Value())
+		}
+		dst.Set(merged)
+	case reflect.Slice, reflect.Array, reflect.String:
+		if src.Len() > 0 {
+			dst.Set(src)
+		}
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
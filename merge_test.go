@@ -0,0 +1,115 @@
+package configstore
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type mergeConfig struct {
+	Name     string            `json:"name,omitempty"`
+	Replicas int               `json:"replicas,omitempty"`
+	Enabled  *bool             `json:"enabled,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Server   struct {
+		Host string `json:"host,omitempty"`
+		Port int    `json:"port,omitempty"`
+	} `json:"server"`
+	Limits *mergeLimits `json:"limits,omitempty"`
+}
+
+type mergeLimits struct {
+	CPU    int `json:"cpu,omitempty"`
+	Memory int `json:"memory,omitempty"`
+}
+
+func TestMerge(t *testing.T) {
+	cs, err := NewConfigStore[mergeConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	enabled := true
+	stored := mergeConfig{Name: "app", Replicas: 3, Enabled: &enabled, Tags: []string{"a", "b"}, Labels: map[string]string{"env": "prod"}}
+	stored.Server.Host = "localhost"
+	stored.Server.Port = 80
+	stored.Limits = &mergeLimits{CPU: 2, Memory: 512}
+	if err := cs.SaveConfig(stored); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 只提供部分字段，指针字段显式设置为 false
+	disabled := false
+	var partial mergeConfig
+	partial.Enabled = &disabled
+	partial.Tags = []string{"c"}
+	partial.Labels = map[string]string{"team": "infra"}
+	partial.Server.Port = 8080
+	partial.Limits = &mergeLimits{Memory: 1024}
+	if err := cs.Merge(partial); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	config, err := cs.LoadConfigOrDefault(mergeConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := stored
+	expected.Enabled = &disabled
+	expected.Tags = []string{"c"}
+	expected.Labels = map[string]string{"env": "prod", "team": "infra"}
+	expected.Server.Port = 8080
+	expected.Limits = &mergeLimits{CPU: 2, Memory: 1024}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected config to be %+v, but got: %+v", expected, config)
+	}
+}
+
+func TestMergeWithoutStoredConfig(t *testing.T) {
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.Merge(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := cs.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config != (myConfig{Username: "testuser"}) {
+		t.Errorf("Expected username testuser, but got: %+v", config)
+	}
+}
+
+func TestMergeTime(t *testing.T) {
+	cs, err := NewConfigStore[timeConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	created := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	if err := cs.SaveConfig(timeConfig{Name: "app", CreatedAt: created}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// time.Time 没有导出的字段，需要作为整体覆盖，零值视为未提供
+	updated := created.Add(time.Hour)
+	if err := cs.Merge(timeConfig{CreatedAt: updated}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := cs.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Name != "app" || !config.CreatedAt.Equal(updated) {
+		t.Errorf("Expected merged created_at %v, but got: %+v", updated, config)
+	}
+
+	if err := cs.Merge(timeConfig{Name: "renamed"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config, _ := cs.LoadConfig(); !config.CreatedAt.Equal(updated) {
+		t.Errorf("Expected created_at to be kept, but got: %v", config.CreatedAt)
+	}
+}
@@ -0,0 +1,107 @@
+package configstore
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WithMetrics 在 reg 中注册读取和保存的 Prometheus 指标：
+// configstore_save_total、configstore_load_total、configstore_errors_total{op} 和 configstore_operation_duration_seconds{op}，
+// 所有指标都带有 file 标签，值为配置文件的文件名。多个 ConfigStore 可以使用同一个 reg，已注册的指标会被复用。
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(c *storeConfig) {
+		c.metrics = reg
+	}
+}
+
+// 一个 ConfigStore 使用的指标
+type storeMetrics struct {
+	saves    prometheus.Counter
+	loads    prometheus.Counter
+	errors   *prometheus.CounterVec
+	duration prometheus.ObserverVec
+}
+
+// 注册指标并绑定 file 标签，reg 为 nil 时返回 nil
+func newStoreMetrics(reg prometheus.Registerer, filename string) (*storeMetrics, error) {
+	if reg == nil {
+		return nil, nil
+	}
+	if filename != "" {
+		filename = filepath.Base(filename)
+	}
+
+	saves, err := registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "configstore_save_total",
+		Help: "Total number of config saves.",
+	}, []string{"file"}))
+	if err != nil {
+		return nil, err
+	}
+	loads, err := registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "configstore_load_total",
+		Help: "Total number of config loads.",
+	}, []string{"file"}))
+	if err != nil {
+		return nil, err
+	}
+	errs, err := registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "configstore_errors_total",
+		Help: "Total number of failed config operations.",
+	}, []string{"op", "file"}))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "configstore_operation_duration_seconds",
+		Help:    "Duration of config operations in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"op", "file"}))
+	if err != nil {
+		return nil, err
+	}
+
+	labels := prometheus.Labels{"file": filename}
+	return &storeMetrics{
+		saves:    saves.With(labels),
+		loads:    loads.With(labels),
+		errors:   errs.MustCurryWith(labels),
+		duration: duration.MustCurryWith(labels),
+	}, nil
+}
+
+// 注册 c，同名的指标已经注册过时返回已注册的指标
+func registerCollector[C prometheus.Collector](reg prometheus.Registerer, c C) (C, error) {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			return c, err
+		}
+		existing, ok := are.ExistingCollector.(C)
+		if !ok {
+			return c, err
+		}
+		return existing, nil
+	}
+	return c, nil
+}
+
+// 记录一次 op 操作，还没有保存过配置不视为错误。m 为 nil 时不做任何事。
+func (m *storeMetrics) observe(op string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	switch op {
+	case "save":
+		m.saves.Inc()
+	case "load":
+		m.loads.Inc()
+	}
+	m.duration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, ErrNoConfig) {
+		m.errors.WithLabelValues(op).Inc()
+	}
+}
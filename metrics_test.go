@@ -0,0 +1,83 @@
+package configstore
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	filename := filepath.Join(t.TempDir(), "metrics.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithMetrics(reg))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 还没有保存过配置不计为错误
+	if _, err := cs.LoadConfigOrDefault(myConfig{}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 同一个 Registerer 可以用于多个 ConfigStore
+	errSave := errors.New("save failed")
+	failing, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(failingBackend{err: errSave}), WithMetrics(reg))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := failing.SaveConfig(myConfig{}); !errors.Is(err, errSave) {
+		t.Fatalf("Expected save error, but got: %v", err)
+	}
+
+	counters := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"configstore_save_total", map[string]string{"file": "metrics.data"}, 1},
+		{"configstore_load_total", map[string]string{"file": "metrics.data"}, 2},
+		{"configstore_errors_total", map[string]string{"op": "load", "file": "metrics.data"}, 0},
+		{"configstore_errors_total", map[string]string{"op": "save", "file": ""}, 1},
+	}
+	for _, c := range counters {
+		if n := gatheredCounter(t, reg, c.name, c.labels); n != c.want {
+			t.Errorf("Expected %s%v to be %v, but got: %v", c.name, c.labels, c.want, n)
+		}
+	}
+	if n := testutil.CollectAndCount(reg, "configstore_operation_duration_seconds"); n != 3 {
+		t.Errorf("Expected 3 duration series, but got: %d", n)
+	}
+}
+
+// 返回 reg 中名为 name、标签为 labels 的计数器的值，不存在时返回 0
+func gatheredCounter(t *testing.T, reg prometheus.Gatherer, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
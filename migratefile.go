@@ -0,0 +1,55 @@
+package configstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// MigrateFile 将 filename 从 from 加密模式转换为 to 加密模式：使用 from 模式读取并解密配置，
+// 再使用 to 模式和新的随机 IV/nonce 重新加密，通过临时文件原子地替换原文件。
+// opts 同时用于读取和写入，需要与保存文件时使用的格式、压缩等 Option 一致。
+// 文件头部已经记录为 to 模式时不做任何修改，因此重复调用是安全的；
+// 头部记录的模式既不是 from 也不是 to 时返回 ErrStoreMismatch。
+func MigrateFile[T any](filename string, key string, from, to CipherMode, opts ...Option) error {
+	data, err := readFile(filename)
+	if os.IsNotExist(err) {
+		err = ErrFileNotFound
+	}
+	if err != nil {
+		return newStoreError("migrate", filename, err)
+	}
+	header, _, err := parseFileHeader(data)
+	if err != nil {
+		return newStoreError("migrate", filename, err)
+	}
+	if header.version != 0 {
+		if header.cipherMode == to {
+			return nil
+		}
+		if header.cipherMode != from {
+			err := fmt.Errorf("%w: cipher mode mismatch: file uses %v, expected %v", ErrStoreMismatch, header.cipherMode, from)
+			return newStoreError("migrate", filename, err)
+		}
+	}
+
+	src, err := New[T](append([]Option{WithFile(filename), WithKey(key)}, append(opts, WithCipherMode(from))...)...)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	// 使用内部读取流程，不应用环境变量也不调用 OnLoad 回调，避免把环境变量的值写入迁移后的文件
+	src.mu.RLock()
+	config, _, err := src.loadConfig(context.Background())
+	src.mu.RUnlock()
+	if err != nil {
+		return src.wrapError("migrate", err)
+	}
+
+	dst, err := New[T](append([]Option{WithFile(filename), WithKey(key)}, append(opts, WithCipherMode(to))...)...)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	return dst.SaveConfig(config)
+}
@@ -0,0 +1,87 @@
+package configstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "migrate.data")
+	key := "0123456789abcdef"
+	cbc, err := New[myConfig](WithFile(filename), WithKey(key), WithCipherMode(CipherModeCBC))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cbc.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := MigrateFile[myConfig](filename, key, CipherModeCBC, CipherModeGCM); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data, _ := os.ReadFile(filename)
+	if header, _, err := parseFileHeader(data); err != nil || header.cipherMode != CipherModeGCM {
+		t.Errorf("Expected file to use GCM, but got: %v, %v", header.cipherMode, err)
+	}
+	gcm, err := New[myConfig](WithFile(filename), WithKey(key), WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := gcm.LoadConfig()
+	if err != nil || config.Username != "testuser" {
+		t.Errorf("Expected username testuser, but got: %+v, %v", config, err)
+	}
+
+	// 已经是目标模式时不修改文件
+	for _, from := range []CipherMode{CipherModeCBC, CipherModeGCM} {
+		if err := MigrateFile[myConfig](filename, key, from, CipherModeGCM); err != nil {
+			t.Errorf("Expected no error, but got: %v", err)
+		}
+		if after, _ := os.ReadFile(filename); !bytes.Equal(after, data) {
+			t.Errorf("Expected file to be unchanged")
+		}
+	}
+
+	// 文件的模式与 from 不一致
+	key32 := "0123456789abcdef0123456789abcdef"
+	if err := MigrateFile[myConfig](filename, key32, CipherModeCBC, CipherModeChaCha20Poly1305); !errors.Is(err, ErrStoreMismatch) {
+		t.Errorf("Expected ErrStoreMismatch, but got: %v", err)
+	}
+	if err := MigrateFile[myConfig](filepath.Join(t.TempDir(), "missing.data"), key, CipherModeCBC, CipherModeGCM); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound, but got: %v", err)
+	}
+}
+
+func TestMigrateFileIgnoresEnvOverride(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "migrate.data")
+	key := "0123456789abcdef"
+	cbc, err := New[envConfig](WithFile(filename), WithKey(key), WithCipherMode(CipherModeCBC))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cbc.SaveConfig(envConfig{Name: "stored", Port: 80}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 环境变量只在读取时覆盖，不应写入迁移后的文件
+	t.Setenv("APP_PORT", "8080")
+	loaded := false
+	err = MigrateFile[envConfig](filename, key, CipherModeCBC, CipherModeGCM, WithEnvOverride("APP"), WithOnLoad(func(envConfig) { loaded = true }))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded {
+		t.Errorf("Expected OnLoad hooks not to run during migration")
+	}
+	gcm, err := New[envConfig](WithFile(filename), WithKey(key), WithCipherMode(CipherModeGCM))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := gcm.LoadConfig()
+	if err != nil || config.Name != "stored" || config.Port != 80 {
+		t.Errorf("Expected stored config without env override, but got: %+v, %v", config, err)
+	}
+}
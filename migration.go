@@ -0,0 +1,61 @@
+package configstore
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Migrator 将数据从上一个版本升级到 Version() 版本。
+// Migrate 的输入和输出都是序列化后的明文（默认为 JSON），在反序列化为 T 之前调用。
+type Migrator interface {
+	Version() int
+	Migrate(rawJSON []byte) ([]byte, error)
+}
+
+// WithMigration 设置当前的数据版本，保存时将版本写入文件头部。
+// 读取到版本低于 currentVersion 的数据时，按版本从小到大依次执行版本高于文件、不超过 currentVersion 的 Migrator。
+// 没有记录版本的旧文件视为版本 0。
+func WithMigration(currentVersion int, migrators ...Migrator) Option {
+	return func(c *storeConfig) {
+		c.dataVersion = currentVersion
+		c.migrators = append([]Migrator(nil), migrators...)
+	}
+}
+
+// 校验迁移配置并按版本排序
+func checkMigrators(cfg *storeConfig) error {
+	if cfg.dataVersion < 0 || cfg.dataVersion > math.MaxUint32 {
+		return fmt.Errorf("%w: invalid data version %d", ErrInvalidOption, cfg.dataVersion)
+	}
+	sort.Slice(cfg.migrators, func(i, j int) bool {
+		return cfg.migrators[i].Version() < cfg.migrators[j].Version()
+	})
+	for i, m := range cfg.migrators {
+		v := m.Version()
+		if v < 1 || v > cfg.dataVersion {
+			return fmt.Errorf("%w: migrator version %d is out of range 1-%d", ErrInvalidOption, v, cfg.dataVersion)
+		}
+		if i > 0 && cfg.migrators[i-1].Version() == v {
+			return fmt.Errorf("%w: duplicate migrator for version %d", ErrInvalidOption, v)
+		}
+	}
+	return nil
+}
+
+// 将版本为 dataVersion 的数据升级到当前版本
+func (cs *ConfigStore[T]) migrate(data []byte, dataVersion int) ([]byte, error) {
+	if dataVersion > cs.dataVersion {
+		return nil, fmt.Errorf("%w: data version %d is newer than %d", ErrUnsupportedVersion, dataVersion, cs.dataVersion)
+	}
+	for _, m := range cs.migrators {
+		if m.Version() <= dataVersion {
+			continue
+		}
+		var err error
+		if data, err = m.Migrate(data); err != nil {
+			return nil, fmt.Errorf("migrate to version %d: %w", m.Version(), err)
+		}
+	}
+	return data, nil
+}
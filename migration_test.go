@@ -0,0 +1,83 @@
+package configstore
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// 版本 1 的配置使用 user 字段，版本 2 改名为 username
+type userConfigV1 struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+type renameUserMigrator struct{}
+
+func (renameUserMigrator) Version() int { return 2 }
+
+func (renameUserMigrator) Migrate(rawJSON []byte) ([]byte, error) {
+	var m map[string]any
+	if err := json.Unmarshal(rawJSON, &m); err != nil {
+		return nil, err
+	}
+	m["username"] = m["user"]
+	delete(m, "user")
+	return json.Marshal(m)
+}
+
+func TestMigration(t *testing.T) {
+	backend := NewMemoryBackend()
+	v1, err := NewConfigStore[userConfigV1]("", "0123456789abcdef", WithBackend(backend), WithMigration(1))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := v1.SaveConfig(userConfigV1{User: "testuser", Password: "testpassword"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data, _ := backend.Read()
+	header, _, err := parseFileHeader(data)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !header.has(flagDataVersion) || header.dataVersion != 1 {
+		t.Errorf("Expected header to record data version 1, but got: %+v", header)
+	}
+
+	v2, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(backend), WithMigration(2, renameUserMigrator{}))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := v2.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := myConfig{Username: "testuser", Password: "testpassword"}
+	if config != expected {
+		t.Errorf("Expected config to be %+v, but got: %+v", expected, config)
+	}
+
+	// 保存后记录为版本 2，再次读取时不会重复迁移
+	if err := v2.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err = v2.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config != expected {
+		t.Errorf("Expected config to be %+v, but got: %+v", expected, config)
+	}
+
+	// 旧版本的程序无法读取新版本的数据
+	if _, err := v1.LoadConfig(); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion, but got: %v", err)
+	}
+}
+
+func TestMigrationInvalidVersion(t *testing.T) {
+	_, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()), WithMigration(1, renameUserMigrator{}))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption, but got: %v", err)
+	}
+}
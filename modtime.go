@@ -0,0 +1,52 @@
+package configstore
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// ForceWrite 与 SaveConfig 相同，但不检查配置文件是否在上一次读取之后被其他进程修改，总是覆盖文件
+func (cs *ConfigStore[T]) ForceWrite(config T) error {
+	ctx, cancel := cs.defaultContext()
+	defer cancel()
+	return cs.save(ctx, config, true)
+}
+
+// 记录配置文件当前的修改时间，只对 FileBackend 生效，调用方需要持有文件锁
+func (cs *ConfigStore[T]) recordModTime() {
+	fb, ok := cs.fileBackend()
+	if !ok {
+		return
+	}
+	var modTime int64
+	if info, err := os.Stat(fb.filename); err == nil {
+		modTime = info.ModTime().UnixNano()
+	}
+	cs.lastLoadedAt.Store(modTime)
+}
+
+// 配置文件的修改时间与上一次读取或写入时记录的不同时返回 ErrExternalModification。
+// 还没有读取过配置或文件已经不存在时不会覆盖其他进程的修改，不需要检查。
+func (cs *ConfigStore[T]) checkModTime() error {
+	fb, ok := cs.fileBackend()
+	if !ok {
+		return nil
+	}
+	last := cs.lastLoadedAt.Load()
+	if last == 0 {
+		return nil
+	}
+	info, err := os.Stat(fb.filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if modTime := info.ModTime(); modTime.UnixNano() != last {
+		return fmt.Errorf("%w: %s was modified at %s, last read version was modified at %s",
+			ErrExternalModification, fb.filename, modTime.Format(time.RFC3339Nano), time.Unix(0, last).Format(time.RFC3339Nano))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExternalModification(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "modtime.data")
+	key := "0123456789abcdef"
+	cs, err := New[myConfig](WithFile(filename), WithKey(key), WithCache())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 还没有读取过配置时可以直接保存
+	if err := cs.SaveConfig(myConfig{Username: "v1"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfigOrDefault(myConfig{}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 连续保存不会被当作外部修改
+	if err := cs.SaveConfig(myConfig{Username: "v2"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 其他进程修改了文件，修改时间设置到未来以避免文件系统时间精度的影响
+	other, err := New[myConfig](WithFile(filename), WithKey(key))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := other.SaveConfig(myConfig{Username: "external"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filename, future, future); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "v3"}); !errors.Is(err, ErrExternalModification) {
+		t.Fatalf("Expected ErrExternalModification, but got: %v", err)
+	}
+	if config, err := other.LoadConfig(); err != nil || config.Username != "external" {
+		t.Errorf("Expected external change to be kept, but got: %+v, %v", config, err)
+	}
+
+	// UpdateConfig 在锁内重新读取文件，不会覆盖外部修改
+	if err := cs.UpdateConfig(func(current myConfig) (myConfig, error) {
+		if current.Username != "external" {
+			t.Errorf("Expected UpdateConfig to see the external change, but got: %+v", current)
+		}
+		current.Password = "updated"
+		return current, nil
+	}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// ForceWrite 总是覆盖文件
+	if err := os.Chtimes(filename, future.Add(time.Hour), future.Add(time.Hour)); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.ForceWrite(myConfig{Username: "forced"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "v4"}); err != nil {
+		t.Errorf("Expected no error after ForceWrite, but got: %v", err)
+	}
+	if config, err := other.LoadConfig(); err != nil || config.Username != "v4" {
+		t.Errorf("Expected username v4, but got: %+v, %v", config, err)
+	}
+}
@@ -0,0 +1,35 @@
+package configstore
+
+import "strings"
+
+// MultiError 是批量操作中所有失败的子操作的错误，例如 Group.LoadAll 和 LoadAll 中每个失败的配置一个错误。
+// 批量操作有失败时返回 *MultiError，可以通过 errors.As 取出后逐个检查，errors.Is 会检查其中的每个错误。
+type MultiError []error
+
+// Error 每行一个错误
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap 返回所有的错误，供 errors.Is 和 errors.As 使用
+func (m MultiError) Unwrap() []error {
+	return m
+}
+
+// 返回 errs 中非 nil 的错误组成的 *MultiError，没有错误时返回 nil
+func newMultiError(errs []error) error {
+	var m MultiError
+	for _, err := range errs {
+		if err != nil {
+			m = append(m, err)
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return &m
+}
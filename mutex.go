@@ -0,0 +1,106 @@
+package configstore
+
+import (
+	"context"
+	"sync"
+)
+
+// ctxRWMutex 是可以在等待时被 ctx 取消的读写锁，零值可以直接使用。
+// 与 sync.RWMutex 相同，有写锁在等待时不再授予新的读锁，避免写入饥饿；
+// 超时放弃等待的调用不会在锁释放后再获取锁，因此不会阻塞之后的读写。
+type ctxRWMutex struct {
+	mu      sync.Mutex
+	readers int
+	writer  bool
+	// 正在等待的写锁数量
+	waiting int
+	// 状态变化时关闭并置空，等待的 goroutine 被唤醒后重新检查
+	wake chan struct{}
+}
+
+func (m *ctxRWMutex) Lock() {
+	m.lock(context.Background(), true)
+}
+
+func (m *ctxRWMutex) RLock() {
+	m.lock(context.Background(), false)
+}
+
+// LockContext 获取写锁，ctx 在获取之前被取消时返回 context.Cause(ctx)
+func (m *ctxRWMutex) LockContext(ctx context.Context) error {
+	return m.lock(ctx, true)
+}
+
+// RLockContext 获取读锁，ctx 在获取之前被取消时返回 context.Cause(ctx)
+func (m *ctxRWMutex) RLockContext(ctx context.Context) error {
+	return m.lock(ctx, false)
+}
+
+func (m *ctxRWMutex) Unlock() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.writer {
+		panic("configstore: unlock of unlocked mutex")
+	}
+	m.writer = false
+	m.broadcast()
+}
+
+func (m *ctxRWMutex) RUnlock() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.readers == 0 {
+		panic("configstore: runlock of unlocked mutex")
+	}
+	m.readers--
+	if m.readers == 0 {
+		m.broadcast()
+	}
+}
+
+func (m *ctxRWMutex) lock(ctx context.Context, write bool) error {
+	m.mu.Lock()
+	if write {
+		m.waiting++
+	}
+	for {
+		if write && !m.writer && m.readers == 0 {
+			m.waiting--
+			m.writer = true
+			m.mu.Unlock()
+			return nil
+		}
+		if !write && !m.writer && m.waiting == 0 {
+			m.readers++
+			m.mu.Unlock()
+			return nil
+		}
+		if m.wake == nil {
+			m.wake = make(chan struct{})
+		}
+		wake := m.wake
+		m.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			m.mu.Lock()
+			if write {
+				// 放弃等待的写锁可能阻塞了读锁
+				m.waiting--
+				m.broadcast()
+			}
+			m.mu.Unlock()
+			return context.Cause(ctx)
+		}
+		m.mu.Lock()
+	}
+}
+
+// 唤醒所有等待的 goroutine，调用方需要持有 m.mu
+func (m *ctxRWMutex) broadcast() {
+	if m.wake != nil {
+		close(m.wake)
+		m.wake = nil
+	}
+}
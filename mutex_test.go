@@ -0,0 +1,49 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCtxRWMutex(t *testing.T) {
+	var m ctxRWMutex
+	m.RLock()
+	if err := m.RLockContext(context.Background()); err != nil {
+		t.Fatalf("Expected readers to share the lock, but got: %v", err)
+	}
+
+	// 持有读锁时获取写锁超时
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.LockContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, but got: %v", err)
+	}
+	// 放弃等待的写锁不再阻塞读锁
+	if err := m.RLockContext(context.Background()); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	m.RUnlock()
+	m.RUnlock()
+	m.RUnlock()
+
+	m.Lock()
+	locked := make(chan struct{})
+	go func() {
+		m.RLock()
+		close(locked)
+		m.RUnlock()
+	}()
+	select {
+	case <-locked:
+		t.Fatal("Expected RLock to wait for the writer")
+	case <-time.After(20 * time.Millisecond):
+	}
+	m.Unlock()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("Expected RLock to succeed after Unlock")
+	}
+}
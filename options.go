@@ -0,0 +1,280 @@
+package configstore
+
+import (
+	"crypto/rand"
+	"io"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultFileMode 是配置文件的默认权限，只有所有者可以读写
+const DefaultFileMode os.FileMode = 0600
+
+// DefaultDirMode 是自动创建配置文件所在目录时使用的默认权限，只有所有者可以访问
+const DefaultDirMode os.FileMode = 0700
+
+// Option 用于在创建 ConfigStore 时调整默认配置
+type Option func(*storeConfig)
+
+// storeConfig 保存通过 Option 设置的内部配置
+type storeConfig struct {
+	filename         string
+	key              []byte
+	cipherMode       CipherMode
+	format           SerializationFormat
+	codec            Codec
+	compression      Compression
+	compressionLevel int
+	kdf              KDF
+	pbkdf2Iterations int
+	argon2Time       uint32
+	argon2Memory     uint32
+	argon2Threads    uint8
+	scryptN          int
+	scryptR          int
+	scryptP          int
+	integrity        bool
+	keyProvider      KeyProvider
+	maxBackups       int
+	backend          Backend
+	watchDebounce    time.Duration
+	fileMode         os.FileMode
+	dirMode          os.FileMode
+	random           io.Reader
+	fileLock         bool
+	tracer           trace.Tracer
+	metrics          prometheus.Registerer
+	logger           Logger
+	pathResolver     PathResolver
+	maxSize          int64
+	sync             bool
+	ttl              time.Duration
+	snapshotDir      string
+	recoveryMode     bool
+	wipePasses       int
+	keyFile          string
+	keyFileMode      os.FileMode
+	checksumFile     bool
+	jsonSchema       []byte
+	retry            bool
+	retryAttempts    int
+	retryBackoff     BackoffPolicy
+	circuitBreaker   bool
+	circuitThreshold int
+	circuitTimeout   time.Duration
+	defaultTimeout   time.Duration
+	// 以 any 保存泛型回调，创建 ConfigStore 时再转换为具体类型
+	onSave    []any
+	onLoad    []any
+	validator any
+	cache     bool
+	// WithMigration 设置的当前数据版本和迁移
+	dataVersion int
+	migrators   []Migrator
+	envOverride bool
+	envPrefix   string
+	// NewConfigStoreWithOverlays 的所有文件，按优先级从低到高排列
+	overlays []string
+}
+
+func defaultStoreConfig() storeConfig {
+	return storeConfig{
+		cipherMode:       CipherModeCBC,
+		format:           FormatJSON,
+		pbkdf2Iterations: DefaultPBKDF2Iterations,
+		argon2Time:       DefaultArgon2idTime,
+		argon2Memory:     DefaultArgon2idMemory,
+		argon2Threads:    DefaultArgon2idThreads,
+		scryptN:          DefaultScryptN,
+		scryptR:          DefaultScryptR,
+		scryptP:          DefaultScryptP,
+		watchDebounce:    DefaultWatchDebounce,
+		fileMode:         DefaultFileMode,
+		dirMode:          DefaultDirMode,
+		random:           rand.Reader,
+		logger:           NewDiscardLogger(),
+		sync:             true,
+		wipePasses:       DefaultSecureDeletePasses,
+		keyFileMode:      DefaultKeyFileMode,
+	}
+}
+
+// WithFile 设置保存配置的文件，文件不存在时会自动创建
+func WithFile(filename string) Option {
+	return func(c *storeConfig) {
+		c.filename = filename
+	}
+}
+
+// WithKey 设置加密 key，长度需要符合加密模式的要求（AES 为 16、24 或 32 字节，ChaCha20-Poly1305 为 32 字节）
+func WithKey(key string) Option {
+	return func(c *storeConfig) {
+		c.key = []byte(key)
+	}
+}
+
+// WithFileMode 设置创建和写入配置文件时使用的权限，默认为 DefaultFileMode。
+// 已存在的文件权限比 mode 宽时，创建 ConfigStore 时会将其收紧为 mode。
+func WithFileMode(mode os.FileMode) Option {
+	return func(c *storeConfig) {
+		c.fileMode = mode
+	}
+}
+
+// WithDirMode 设置配置文件所在目录不存在时自动创建目录使用的权限，默认为 DefaultDirMode。
+// 已存在的目录权限不会被修改。
+func WithDirMode(mode os.FileMode) Option {
+	return func(c *storeConfig) {
+		c.dirMode = mode
+	}
+}
+
+// WithSync 设置写入配置文件后是否调用 fsync 将数据和目录项刷新到磁盘，默认为 true。
+// 不刷新时写入的数据可能仍然在操作系统的页缓存中，断电后会丢失最近的修改（原文件不会损坏）；
+// 对持久性要求不高、写入频繁的场景可以关闭以提高吞吐量。使用 WithBackend 时由后端自行决定。
+func WithSync(sync bool) Option {
+	return func(c *storeConfig) {
+		c.sync = sync
+	}
+}
+
+// WithRandReader 设置生成 IV/nonce 和盐使用的随机数来源，默认为 crypto/rand.Reader。
+// 仅用于需要得到确定输出的测试，生产环境中使用可预测的随机数会破坏加密的安全性。
+func WithRandReader(r io.Reader) Option {
+	return func(c *storeConfig) {
+		if r == nil {
+			r = rand.Reader
+		}
+		c.random = r
+	}
+}
+
+// WithCipherMode 设置加密模式，默认为 CipherModeCBC
+func WithCipherMode(mode CipherMode) Option {
+	return func(c *storeConfig) {
+		c.cipherMode = mode
+	}
+}
+
+// WithFormat 设置序列化格式，默认为 FormatJSON
+func WithFormat(f SerializationFormat) Option {
+	return func(c *storeConfig) {
+		c.format = f
+		c.codec = nil
+	}
+}
+
+// WithCodec 使用自定义的 Codec 进行序列化，文件头部的格式记录为 FormatCustom
+func WithCodec(codec Codec) Option {
+	return func(c *storeConfig) {
+		c.format = FormatCustom
+		c.codec = codec
+	}
+}
+
+// WithCompression 在加密前使用 gzip 压缩序列化后的数据，level 为 compress/gzip 的压缩级别。
+// 未压缩的旧文件仍可以正常读取。
+func WithCompression(level int) Option {
+	return func(c *storeConfig) {
+		c.compression = CompressionGzip
+		c.compressionLevel = level
+	}
+}
+
+// WithCompressionAlgorithm 设置压缩算法和压缩级别：gzip 使用 compress/gzip 的级别，zstd 使用 1-22 级
+func WithCompressionAlgorithm(compression Compression, level int) Option {
+	return func(c *storeConfig) {
+		c.compression = compression
+		c.compressionLevel = level
+	}
+}
+
+// WithKDF 设置由密码派生 key 的算法，仅对 NewConfigStoreFromPassword 生效
+func WithKDF(kdf KDF) Option {
+	return func(c *storeConfig) {
+		c.kdf = kdf
+	}
+}
+
+// WithPBKDF2Iterations 设置 PBKDF2 的迭代次数，默认为 DefaultPBKDF2Iterations，不能超过 MaxPBKDF2Iterations
+func WithPBKDF2Iterations(iterations int) Option {
+	return func(c *storeConfig) {
+		c.pbkdf2Iterations = iterations
+	}
+}
+
+// WithArgon2idParams 设置 Argon2id 的参数：迭代次数、内存（KiB）和并行度
+// 参数不能超过 MaxArgon2idTime、MaxArgon2idMemory 和 MaxArgon2idThreads
+func WithArgon2idParams(time, memory uint32, threads uint8) Option {
+	return func(c *storeConfig) {
+		c.argon2Time = time
+		c.argon2Memory = memory
+		c.argon2Threads = threads
+	}
+}
+
+// WithScryptParams 设置 scrypt 的参数：CPU/内存开销 N（大于 1 的 2 的幂）、块大小 r 和并行度 p
+// 参数不能超过 MaxScryptN、MaxScryptR 和 MaxScryptP，且内存用量 128*N*r 不能超过 MaxScryptMemory
+func WithScryptParams(n, r, p int) Option {
+	return func(c *storeConfig) {
+		c.scryptN = n
+		c.scryptR = r
+		c.scryptP = p
+	}
+}
+
+// WithIntegrity 在密文前附加 HMAC-SHA256 标签，读取时先校验标签再解密。
+// 未启用该选项时写入的文件不包含标签，需要在同样未启用该选项的 ConfigStore 中读取。
+func WithIntegrity() Option {
+	return func(c *storeConfig) {
+		c.integrity = true
+	}
+}
+
+// WithKeyProvider 设置 KeyProvider，设置后忽略 WithKey 或构造函数中的 key 参数，每次加密或解密前都会调用 GetKey
+func WithKeyProvider(kp KeyProvider) Option {
+	return func(c *storeConfig) {
+		c.keyProvider = kp
+	}
+}
+
+// WithBackup 在每次保存前将当前文件备份为 filename.bak.1，旧的备份依次后移，最多保留 maxBackups 个。
+// 仅对 FileBackend 生效。
+func WithBackup(maxBackups int) Option {
+	return func(c *storeConfig) {
+		c.maxBackups = maxBackups
+	}
+}
+
+// WithBackend 设置存储后端，设置后不再检查或创建 filename 对应的文件
+func WithBackend(b Backend) Option {
+	return func(c *storeConfig) {
+		c.backend = b
+	}
+}
+
+// WithWatchDebounce 设置 Watch 合并连续写入事件的间隔，默认为 DefaultWatchDebounce
+func WithWatchDebounce(d time.Duration) Option {
+	return func(c *storeConfig) {
+		c.watchDebounce = d
+	}
+}
+
+// WithOnSave 注册在 SaveConfig 成功后调用的回调，多次使用时按注册顺序依次调用。
+// 回调在锁外执行，可以在回调中再次调用 SaveConfig 或 LoadConfig。
+func WithOnSave[T any](fn func(T)) Option {
+	return func(c *storeConfig) {
+		c.onSave = append(c.onSave, fn)
+	}
+}
+
+// WithOnLoad 注册在成功读取到已保存的配置后调用的回调，多次使用时按注册顺序依次调用。
+// 返回默认配置时不会调用。回调同样在锁外执行。
+func WithOnLoad[T any](fn func(T)) Option {
+	return func(c *storeConfig) {
+		c.onLoad = append(c.onLoad, fn)
+	}
+}
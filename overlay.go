@@ -0,0 +1,66 @@
+package configstore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// NewConfigStoreWithOverlays 创建一个从多个文件读取配置的 ConfigStore，例如系统配置、用户配置和当前目录的配置。
+// 读取时按顺序读取所有存在的文件并深度合并，后面的文件优先，合并规则与 Merge 相同；不存在或为空的文件会被跳过。
+// SaveConfig 只写入第一个文件。所有文件需要使用相同的 key 和 Option 写入。
+func NewConfigStoreWithOverlays[T any](files []string, key string, opts ...Option) (*ConfigStore[T], error) {
+	if len(files) == 0 {
+		return nil, newStoreError("open", "", fmt.Errorf("%w: at least one file is required", ErrInvalidOption))
+	}
+	overlays := append([]string(nil), files...)
+	opts = append([]Option{WithFile(files[0]), WithKey(key)}, opts...)
+	opts = append(opts, func(c *storeConfig) {
+		c.overlays = overlays
+		// 覆盖文件只能是本地文件
+		c.backend = nil
+	})
+	return New[T](opts...)
+}
+
+// 依次读取所有覆盖文件并合并，保存时间取最早的一层，调用方需要持有锁
+func (cs *ConfigStore[T]) loadOverlays(ctx context.Context) (T, time.Time, error) {
+	var config T
+	secret, err := cs.secret(ctx)
+	if err != nil {
+		return config, time.Time{}, err
+	}
+
+	found := false
+	var savedAt time.Time
+	dst := reflect.ValueOf(&config).Elem()
+	for i, filename := range cs.overlays {
+		var fileData []byte
+		if i == 0 {
+			// 第一个文件是 SaveConfig 写入的文件，与 loadConfig 一样记录修改时间并校验校验文件
+			fileData, err = cs.readBackend(ctx)
+		} else {
+			fileData, err = NewFileBackend(filename).Read()
+		}
+		if err != nil {
+			return config, time.Time{}, err
+		}
+		if len(fileData) == 0 {
+			continue
+		}
+		layer, layerSavedAt, err := cs.decode(secret, fileData)
+		if err != nil {
+			return config, time.Time{}, fmt.Errorf("%s: %w", filename, err)
+		}
+		if savedAt.IsZero() || layerSavedAt.Before(savedAt) {
+			savedAt = layerSavedAt
+		}
+		mergeValue(dst, reflect.ValueOf(layer))
+		found = true
+	}
+	if !found {
+		return config, time.Time{}, ErrNoConfig
+	}
+	return config, savedAt, cs.validate(config)
+}
@@ -0,0 +1,130 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type overlayConfig struct {
+	Name  string   `json:"name,omitempty"`
+	Port  int      `json:"port,omitempty"`
+	Debug bool     `json:"debug,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func saveOverlay(t *testing.T, filename string, config overlayConfig) {
+	t.Helper()
+	cs, err := NewConfigStore[overlayConfig](filename, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+}
+
+func TestOverlays(t *testing.T) {
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.data")
+	user := filepath.Join(dir, "user.data")
+	local := filepath.Join(dir, "local.data")
+	saveOverlay(t, system, overlayConfig{Name: "system", Port: 80, Tags: []string{"a"}})
+	saveOverlay(t, user, overlayConfig{Port: 8080, Debug: true})
+
+	// local.data 不存在，直接跳过
+	cs, err := NewConfigStoreWithOverlays[overlayConfig]([]string{system, user, local}, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := cs.LoadConfigOrDefault(overlayConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Name != "system" || config.Port != 8080 || !config.Debug || len(config.Tags) != 1 {
+		t.Errorf("Expected merged config, but got: %+v", config)
+	}
+
+	// 只写入第一个文件，覆盖文件保持不变
+	if err := cs.SaveConfig(overlayConfig{Name: "saved", Port: 9090}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	base, err := NewConfigStore[overlayConfig](system, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config, _ := base.LoadConfigOrDefault(overlayConfig{}); config.Name != "saved" || config.Port != 9090 {
+		t.Errorf("Expected first file to contain the saved config, but got: %+v", config)
+	}
+	config, err = cs.LoadConfigOrDefault(overlayConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Name != "saved" || config.Port != 8080 {
+		t.Errorf("Expected user file to still override the port, but got: %+v", config)
+	}
+}
+
+func TestOverlaysNoConfig(t *testing.T) {
+	dir := t.TempDir()
+	cs, err := NewConfigStoreWithOverlays[overlayConfig]([]string{filepath.Join(dir, "a.data"), filepath.Join(dir, "b.data")}, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+	if _, err := NewConfigStoreWithOverlays[overlayConfig](nil, "0123456789abcdef"); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption, but got: %v", err)
+	}
+}
+
+func TestOverlaysExternalModification(t *testing.T) {
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.data")
+	user := filepath.Join(dir, "user.data")
+	saveOverlay(t, system, overlayConfig{Name: "system"})
+	saveOverlay(t, user, overlayConfig{Port: 8080})
+
+	cs, err := NewConfigStoreWithOverlays[overlayConfig]([]string{system, user}, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 读取之后其他进程修改了第一个文件，保存时不应覆盖该修改
+	saveOverlay(t, system, overlayConfig{Name: "external"})
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(system, future, future); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(overlayConfig{Name: "saved"}); !errors.Is(err, ErrExternalModification) {
+		t.Errorf("Expected ErrExternalModification, but got: %v", err)
+	}
+}
+
+func TestOverlaysChecksumFile(t *testing.T) {
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.data")
+	cs, err := NewConfigStoreWithOverlays[overlayConfig]([]string{system}, "0123456789abcdef", WithChecksumFile())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(overlayConfig{Name: "system"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := os.WriteFile(checksumName(system), []byte("0000\n"), 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, but got: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package configstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PatchConfig 读取当前保存的配置，将 patch 作为 JSON Merge Patch（RFC 7396）应用到配置的 JSON 表示上，
+// 解析回 T 后保存。patch 中值为 null 的字段会被删除，即恢复为零值；对象逐个 key 合并，其他值（包括数组）整体替换。
+// 还没有保存过配置时从 T 的零值开始。配置的 JSON 表示遵循 json 标签，与 WithFormat 设置的序列化格式无关。
+func (cs *ConfigStore[T]) PatchConfig(patch []byte) error {
+	cs.mu.Lock()
+	config, err := cs.patchConfig(patch)
+	cs.mu.Unlock()
+	if err != nil {
+		return cs.wrapError("patch", err)
+	}
+
+	for _, fn := range cs.onSave {
+		fn(config)
+	}
+	return nil
+}
+
+// 应用 patch 并保存，调用方需要持有写锁
+func (cs *ConfigStore[T]) patchConfig(patch []byte) (T, error) {
+	var zero T
+	if !json.Valid(patch) {
+		return zero, fmt.Errorf("%w: invalid merge patch", ErrInvalidConfig)
+	}
+	current, _, err := cs.loadConfig(context.Background())
+	if err != nil && !errors.Is(err, ErrNoConfig) {
+		return zero, err
+	}
+	target, err := json.Marshal(current)
+	if err != nil {
+		return zero, err
+	}
+	merged, err := mergePatch(target, patch)
+	if err != nil {
+		return zero, err
+	}
+	// 解析到新的值中，被删除的字段为零值
+	var config T
+	if err := json.Unmarshal(merged, &config); err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return config, cs.saveConfig(context.Background(), config, false)
+}
+
+// 按 RFC 7396 将 patch 合并到 target，两者都是合法的 JSON
+func mergePatch(target, patch json.RawMessage) (json.RawMessage, error) {
+	if !isJSONObject(patch) {
+		return patch, nil
+	}
+	var patchFields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &patchFields); err != nil {
+		return nil, err
+	}
+	// target 不是对象时视为空对象
+	targetFields := make(map[string]json.RawMessage)
+	if isJSONObject(target) {
+		if err := json.Unmarshal(target, &targetFields); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range patchFields {
+		if string(bytes.TrimSpace(value)) == "null" {
+			delete(targetFields, name)
+			continue
+		}
+		merged, err := mergePatch(targetFields[name], value)
+		if err != nil {
+			return nil, err
+		}
+		targetFields[name] = merged
+	}
+	return json.Marshal(targetFields)
+}
+
+func isJSONObject(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
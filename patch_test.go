@@ -0,0 +1,111 @@
+package configstore
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type patchConfig struct {
+	Name     string            `json:"name"`
+	Port     int               `json:"port,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Database *patchDatabase    `json:"database,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+type patchDatabase struct {
+	Host string `json:"host"`
+	User string `json:"user"`
+}
+
+func TestPatchConfig(t *testing.T) {
+	cs, err := New[patchConfig](WithFile(filepath.Join(t.TempDir(), "patch.data")), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 还没有保存过配置时从零值开始
+	if err := cs.PatchConfig([]byte(`{"name":"api","database":{"host":"db1","user":"admin"},"tags":["a","b"]}`)); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		patch    string
+		expected patchConfig
+	}{
+		{
+			name:     "add",
+			patch:    `{"port":8080,"labels":{"env":"prod","team":"core"}}`,
+			expected: patchConfig{Name: "api", Port: 8080, Tags: []string{"a", "b"}, Database: &patchDatabase{Host: "db1", User: "admin"}, Labels: map[string]string{"env": "prod", "team": "core"}},
+		},
+		{
+			// 嵌套对象逐个 key 合并，数组整体替换
+			name:     "modify",
+			patch:    `{"name":"web","database":{"host":"db2"},"tags":["c"]}`,
+			expected: patchConfig{Name: "web", Port: 8080, Tags: []string{"c"}, Database: &patchDatabase{Host: "db2", User: "admin"}, Labels: map[string]string{"env": "prod", "team": "core"}},
+		},
+		{
+			name:     "remove",
+			patch:    `{"port":null,"labels":{"team":null},"database":null}`,
+			expected: patchConfig{Name: "web", Tags: []string{"c"}, Labels: map[string]string{"env": "prod"}},
+		},
+	}
+	for _, tt := range tests {
+		if err := cs.PatchConfig([]byte(tt.patch)); err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", tt.name, err)
+		}
+		config, err := cs.LoadConfig()
+		if err != nil {
+			t.Fatalf("%s: expected no error, but got: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(config, tt.expected) {
+			t.Errorf("%s: expected %+v, but got: %+v", tt.name, tt.expected, config)
+		}
+	}
+
+	// 不合法的 patch 和类型不匹配的 patch 都不会修改已保存的配置
+	for _, patch := range []string{`{"name":`, `{"port":"not a number"}`} {
+		if err := cs.PatchConfig([]byte(patch)); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for %s, but got: %v", patch, err)
+		}
+	}
+	if config, _ := cs.LoadConfig(); !reflect.DeepEqual(config, tests[len(tests)-1].expected) {
+		t.Errorf("Expected config to be unchanged, but got: %+v", config)
+	}
+}
+
+// RFC 7396 附录 A 中的部分示例
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		target, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		merged, err := mergePatch(json.RawMessage(tt.target), json.RawMessage(tt.patch))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var got, expected any
+		json.Unmarshal(merged, &got)
+		json.Unmarshal([]byte(tt.expected), &expected)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %s patched with %s to be %s, but got: %s", tt.target, tt.patch, tt.expected, merged)
+		}
+	}
+}
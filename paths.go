@@ -0,0 +1,155 @@
+package configstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// 平台配置目录中的配置文件名
+const configFileName = "config.data"
+
+// PathResolver 根据应用名计算配置文件的路径
+type PathResolver interface {
+	ResolvePath(appName string) (string, error)
+}
+
+// WithPathResolver 使用 pr 计算配置文件的路径：WithFile（或 NewConfigStore 的 filename 参数）的值作为 appName 传给 pr，
+// 配置文件所在的目录不存在时以 0700 权限创建。使用 WithBackend 时不生效。
+func WithPathResolver(pr PathResolver) Option {
+	return func(c *storeConfig) {
+		c.pathResolver = pr
+	}
+}
+
+// DefaultPathResolver 返回当前平台标准配置目录的 PathResolver：
+// Windows 为 WindowsPathResolver，macOS 为 MacOSPathResolver，其他系统为 XDGPathResolver。
+func DefaultPathResolver() PathResolver {
+	switch runtime.GOOS {
+	case "windows":
+		return WindowsPathResolver{}
+	case "darwin", "ios":
+		return MacOSPathResolver{}
+	default:
+		return XDGPathResolver{}
+	}
+}
+
+// NewXDGConfigStore 使用平台标准的配置目录创建 ConfigStore，配置文件为 <dir>/<appName>/config.data，其中 dir 为：
+//   - Linux 等系统：$XDG_CONFIG_HOME，未设置时为 $HOME/.config
+//   - macOS：$HOME/Library/Application Support
+//   - Windows：%APPDATA%
+//
+// 目录不存在时以 0700 权限创建。
+func NewXDGConfigStore[T any](appName string, key string, opts ...Option) (*ConfigStore[T], error) {
+	return New[T](append([]Option{WithFile(appName), WithKey(key), WithPathResolver(DefaultPathResolver())}, opts...)...)
+}
+
+// XDGPathResolver 按照 XDG Base Directory 规范返回 $XDG_CONFIG_HOME/<appName>/config.data，
+// 未设置 $XDG_CONFIG_HOME 时使用 $HOME/.config
+type XDGPathResolver struct {
+	// 测试时替换 os.Getenv
+	getenv func(string) string
+}
+
+func (r XDGPathResolver) ResolvePath(appName string) (string, error) {
+	if err := checkAppName(appName); err != nil {
+		return "", err
+	}
+	getenv := getenvOrDefault(r.getenv)
+	// XDG 规范要求忽略相对路径
+	dir := getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(dir) {
+		home := getenv("HOME")
+		if home == "" {
+			return "", fmt.Errorf("%w: neither $XDG_CONFIG_HOME nor $HOME is set", ErrInvalidOption)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, appName, configFileName), nil
+}
+
+// MacOSPathResolver 返回 $HOME/Library/Application Support/<appName>/config.data
+type MacOSPathResolver struct {
+	getenv func(string) string
+}
+
+func (r MacOSPathResolver) ResolvePath(appName string) (string, error) {
+	if err := checkAppName(appName); err != nil {
+		return "", err
+	}
+	home := getenvOrDefault(r.getenv)("HOME")
+	if home == "" {
+		return "", fmt.Errorf("%w: $HOME is not set", ErrInvalidOption)
+	}
+	return filepath.Join(home, "Library", "Application Support", appName, configFileName), nil
+}
+
+// WindowsPathResolver 返回 %APPDATA%\<appName>\config.data
+type WindowsPathResolver struct {
+	getenv func(string) string
+}
+
+func (r WindowsPathResolver) ResolvePath(appName string) (string, error) {
+	if err := checkAppName(appName); err != nil {
+		return "", err
+	}
+	dir := getenvOrDefault(r.getenv)("APPDATA")
+	if dir == "" {
+		return "", fmt.Errorf("%w: %%APPDATA%% is not set", ErrInvalidOption)
+	}
+	return filepath.Join(dir, appName, configFileName), nil
+}
+
+// StaticPathResolver 将 appName 原样作为配置文件的路径，与不设置 WithPathResolver 时的行为相同
+type StaticPathResolver struct{}
+
+func (StaticPathResolver) ResolvePath(filename string) (string, error) {
+	return filename, nil
+}
+
+// TempDirResolver 返回 Dir/<appName>/config.data，Dir 为空时使用 os.TempDir()，
+// 通常在测试中配合 t.TempDir() 使用，使每个测试使用独立的目录
+type TempDirResolver struct {
+	Dir string
+}
+
+func (r TempDirResolver) ResolvePath(appName string) (string, error) {
+	if err := checkAppName(appName); err != nil {
+		return "", err
+	}
+	dir := r.Dir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, appName, configFileName), nil
+}
+
+// 使用 pr 计算配置文件路径并创建所在的目录
+func resolveFilename(pr PathResolver, appName string, dirMode os.FileMode) (string, error) {
+	filename, err := pr.ResolvePath(appName)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), dirMode); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// appName 会作为目录名，不能为空或包含路径分隔符
+func checkAppName(appName string) error {
+	if appName == "" || appName == "." || appName == ".." || strings.ContainsAny(appName, `/\`) {
+		return fmt.Errorf("%w: invalid app name %q", ErrInvalidOption, appName)
+	}
+	return nil
+}
+
+func getenvOrDefault(getenv func(string) string) func(string) string {
+	if getenv == nil {
+		return os.Getenv
+	}
+	return getenv
+}
@@ -0,0 +1,98 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// 返回只包含 env 中变量的 getenv
+func fakeGetenv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestPathResolvers(t *testing.T) {
+	cases := []struct {
+		name     string
+		resolver PathResolver
+		want     string
+	}{
+		{"xdg", XDGPathResolver{getenv: fakeGetenv(map[string]string{"XDG_CONFIG_HOME": "/xdg", "HOME": "/home/user"})}, filepath.Join("/xdg", "myapp", "config.data")},
+		{"home fallback", XDGPathResolver{getenv: fakeGetenv(map[string]string{"HOME": "/home/user"})}, filepath.Join("/home/user", ".config", "myapp", "config.data")},
+		{"relative xdg ignored", XDGPathResolver{getenv: fakeGetenv(map[string]string{"XDG_CONFIG_HOME": "xdg", "HOME": "/home/user"})}, filepath.Join("/home/user", ".config", "myapp", "config.data")},
+		{"macos", MacOSPathResolver{getenv: fakeGetenv(map[string]string{"HOME": "/Users/user", "XDG_CONFIG_HOME": "/xdg"})}, filepath.Join("/Users/user", "Library", "Application Support", "myapp", "config.data")},
+		{"windows", WindowsPathResolver{getenv: fakeGetenv(map[string]string{"APPDATA": `C:\Users\user\AppData\Roaming`})}, filepath.Join(`C:\Users\user\AppData\Roaming`, "myapp", "config.data")},
+		{"temp dir", TempDirResolver{Dir: "/tmp/test"}, filepath.Join("/tmp/test", "myapp", "config.data")},
+		{"static", StaticPathResolver{}, "myapp"},
+	}
+	for _, c := range cases {
+		got, err := c.resolver.ResolvePath("myapp")
+		if err != nil {
+			t.Errorf("%s: expected no error, but got: %v", c.name, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s: expected %s, but got: %s", c.name, c.want, got)
+		}
+	}
+}
+
+func TestPathResolverInvalid(t *testing.T) {
+	resolver := XDGPathResolver{getenv: fakeGetenv(map[string]string{"HOME": "/home/user"})}
+	for _, name := range []string{"", "..", "a/b", `a\b`} {
+		if _, err := resolver.ResolvePath(name); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%q: expected ErrInvalidOption, but got: %v", name, err)
+		}
+	}
+	// 缺少需要的环境变量
+	empty := fakeGetenv(nil)
+	for _, r := range []PathResolver{XDGPathResolver{getenv: empty}, MacOSPathResolver{getenv: empty}, WindowsPathResolver{getenv: empty}} {
+		if _, err := r.ResolvePath("myapp"); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%T: expected ErrInvalidOption, but got: %v", r, err)
+		}
+	}
+}
+
+func TestWithPathResolver(t *testing.T) {
+	dir := t.TempDir()
+	cs, err := NewConfigStore[myConfig]("myapp", "0123456789abcdef", WithPathResolver(TempDirResolver{Dir: dir}))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "myapp", "config.data")); err != nil {
+		t.Errorf("Expected config file in the resolved path, but got: %v", err)
+	}
+}
+
+func TestNewXDGConfigStore(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("uses XDG_CONFIG_HOME")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	cs, err := NewXDGConfigStore[myConfig]("myapp", "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "myapp"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("Expected directory mode 0700, but got: %v", info.Mode().Perm())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "myapp", "config.data")); err != nil {
+		t.Errorf("Expected config file to exist, but got: %v", err)
+	}
+}
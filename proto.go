@@ -0,0 +1,37 @@
+package configstore
+
+import (
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtoCodec 使用 Protocol Buffers 二进制格式序列化配置，通过 WithCodec(ProtoCodec{}) 使用。
+// 配置类型需要是 protoc-gen-go 生成的消息指针类型（例如 *pb.Config），否则返回 ErrNotProtoMessage。
+type ProtoCodec struct{}
+
+func (ProtoCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+	return proto.Marshal(m)
+}
+
+func (ProtoCodec) Unmarshal(data []byte, v any) error {
+	// v 是指向消息指针的指针，消息指针为 nil 时先分配
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Pointer {
+		return fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+	m, ok := reflect.Zero(rv.Elem().Type()).Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrNotProtoMessage, rv.Elem().Interface())
+	}
+	if rv.Elem().IsNil() {
+		rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+	}
+	m = rv.Elem().Interface().(proto.Message)
+	return proto.Unmarshal(data, m)
+}
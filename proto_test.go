@@ -0,0 +1,94 @@
+package configstore
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/JanusHuang/configstore/internal/testpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func newProtoConfig() *testpb.Config {
+	return &testpb.Config{
+		Name:           "api",
+		Host:           "db.example.com",
+		Port:           5432,
+		Tls:            true,
+		Username:       "testuser",
+		Password:       "secret",
+		TimeoutMs:      30000,
+		MaxConnections: 100,
+		MaxIdle:        10,
+		SampleRate:     0.25,
+		Debug:          true,
+		LogLevel:       "info",
+		Region:         "us-east-1",
+		Zone:           "us-east-1a",
+		Tags:           []string{"primary", "billing"},
+		Peers:          []string{"10.0.0.1:5432", "10.0.0.2:5432"},
+		Retries:        3,
+		Version:        42,
+		Weight:         1.5,
+		Token:          []byte{0x01, 0x02, 0x03},
+	}
+}
+
+func TestProtoCodec(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "proto.data")
+	cs, err := New[*testpb.Config](WithFile(filename), WithKey("0123456789abcdef"), WithCodec(ProtoCodec{}))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := newProtoConfig()
+	if err := cs.SaveConfig(expected); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := cs.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !proto.Equal(config, expected) {
+		t.Errorf("Expected %v, but got: %v", expected, config)
+	}
+}
+
+func TestProtoCodecNotProtoMessage(t *testing.T) {
+	cs, err := NewEphemeralStore[myConfig]("0123456789abcdef", WithCodec(ProtoCodec{}))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); !errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("Expected ErrNotProtoMessage, but got: %v", err)
+	}
+	var config *myConfig
+	if err := (ProtoCodec{}).Unmarshal(nil, &config); !errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("Expected ErrNotProtoMessage, but got: %v", err)
+	}
+}
+
+// 比较 20 个字段的消息使用 JSON 和 Protocol Buffers 序列化的性能
+func BenchmarkCodec(b *testing.B) {
+	for _, c := range []struct {
+		name  string
+		codec Codec
+	}{
+		{"json", jsonCodec{}},
+		{"proto", ProtoCodec{}},
+	} {
+		b.Run("codec="+c.name, func(b *testing.B) {
+			config := newProtoConfig()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				data, err := c.codec.Marshal(config)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var decoded *testpb.Config
+				if err := c.codec.Unmarshal(data, &decoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package configstore
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReadOnlyStore 是只读取配置的 ConfigStore，由 NewReadOnlyStore 返回。
+// 接口中没有写入方法，只需要读取配置的服务在编译期就无法写入配置文件。
+type ReadOnlyStore[T any] interface {
+	LoadConfigOrDefault(defaultConfig T) (T, error)
+	Stats() (ConfigStats, error)
+	Watch(ctx context.Context, onChange func(T, error)) (cancel func(), err error)
+}
+
+type readOnlyStore[T any] struct {
+	cs *ConfigStore[T]
+}
+
+// NewReadOnlyStore 使用 filename 和 key 创建只读的 ConfigStore，文件不存在时不会创建文件。
+// 通过类型断言调用返回值的 SaveConfig 会 panic。opts 中的 WithBackend 会被忽略，
+// 会写入磁盘的 WithRecoveryMode、WithBackup、WithAutoSnapshot 和 WithOnSave 返回 ErrInvalidOption。
+func NewReadOnlyStore[T any](filename string, key string, opts ...Option) (ReadOnlyStore[T], error) {
+	cfg := defaultStoreConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if err := cfg.checkReadOnly(); err != nil {
+		return nil, newStoreError("open", filename, err)
+	}
+	// 最后设置后端，保证读取的总是 filename
+	cs, err := New[T](append(append([]Option{WithKey(key)}, opts...), WithBackend(NewFileBackend(filename)))...)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyStore[T]{cs: cs}, nil
+}
+
+// 检查配置中是否有会写入磁盘的选项
+func (c *storeConfig) checkReadOnly() error {
+	var option string
+	switch {
+	case c.recoveryMode:
+		option = "WithRecoveryMode"
+	case c.maxBackups > 0:
+		option = "WithBackup"
+	case c.snapshotDir != "":
+		option = "WithAutoSnapshot"
+	case len(c.onSave) > 0:
+		option = "WithOnSave"
+	default:
+		return nil
+	}
+	return fmt.Errorf("%w: %s is not allowed on a read-only store", ErrInvalidOption, option)
+}
+
+func (s *readOnlyStore[T]) LoadConfigOrDefault(defaultConfig T) (T, error) {
+	return s.cs.LoadConfigOrDefault(defaultConfig)
+}
+
+func (s *readOnlyStore[T]) Stats() (ConfigStats, error) {
+	return s.cs.Stats()
+}
+
+func (s *readOnlyStore[T]) Watch(ctx context.Context, onChange func(T, error)) (cancel func(), err error) {
+	return s.cs.Watch(ctx, onChange)
+}
+
+// SaveConfig 总是 panic，只读的 ConfigStore 不允许写入
+func (s *readOnlyStore[T]) SaveConfig(config T) error {
+	panic("configstore: save called on read-only store")
+}
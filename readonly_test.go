@@ -0,0 +1,119 @@
+package configstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewReadOnlyStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "readonly.data")
+	key := "0123456789abcdef"
+
+	// 文件不存在时返回默认配置，不创建文件
+	ro, err := NewReadOnlyStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := ro.LoadConfigOrDefault(myConfig{Username: "default"})
+	if err != nil || config.Username != "default" {
+		t.Errorf("Expected default config, but got: %+v, %v", config, err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("Expected file not to be created, but got: %v", err)
+	}
+
+	cs, err := New[myConfig](WithFile(filename), WithKey(key))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err = ro.LoadConfigOrDefault(myConfig{})
+	if err != nil || config.Username != "testuser" {
+		t.Errorf("Expected username testuser, but got: %+v, %v", config, err)
+	}
+	if stats, err := ro.Stats(); err != nil || !stats.Exists {
+		t.Errorf("Expected stats of an existing file, but got: %+v, %v", stats, err)
+	}
+
+	// 通过类型断言调用 SaveConfig 会 panic
+	saver, ok := ro.(interface{ SaveConfig(myConfig) error })
+	if !ok {
+		t.Fatal("Expected read-only store to have SaveConfig")
+	}
+	defer func() {
+		if r := recover(); r != "configstore: save called on read-only store" {
+			t.Errorf("Expected SaveConfig to panic, but got: %v", r)
+		}
+	}()
+	saver.SaveConfig(myConfig{})
+}
+
+func TestReadOnlyStoreRejectsWriteOptions(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "readonly.data")
+	key := "0123456789abcdef"
+	cs, err := New[myConfig](WithFile(filename), WithKey(key), WithBackup(2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	corruptFile(t, filename)
+	corrupted, _ := os.ReadFile(filename)
+
+	// 主文件损坏时恢复模式会用备份覆盖主文件，只读的 ConfigStore 不允许启用
+	_, err = NewReadOnlyStore[myConfig](filename, key, WithRecoveryMode())
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for WithRecoveryMode, but got: %v", err)
+	}
+	ro, err := NewReadOnlyStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := ro.LoadConfigOrDefault(myConfig{}); !isCorruption(err) {
+		t.Errorf("Expected a corruption error, but got: %v", err)
+	}
+	if data, _ := os.ReadFile(filename); !bytes.Equal(data, corrupted) {
+		t.Error("Expected the corrupted file to be left untouched")
+	}
+
+	for name, opt := range map[string]Option{
+		"WithBackup":       WithBackup(1),
+		"WithAutoSnapshot": WithAutoSnapshot(filepath.Join(dir, "snapshots")),
+		"WithOnSave":       WithOnSave(func(myConfig) {}),
+	} {
+		if _, err := NewReadOnlyStore[myConfig](filename, key, opt); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption for %s, but got: %v", name, err)
+		}
+	}
+}
+
+func TestReadOnlyStoreIgnoresBackend(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "readonly.data")
+	key := "0123456789abcdef"
+	cs, err := New[myConfig](WithFile(filename), WithKey(key))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 调用方传入的后端不能替换 filename
+	ro, err := NewReadOnlyStore[myConfig](filename, key, WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := ro.LoadConfigOrDefault(myConfig{})
+	if err != nil || config.Username != "testuser" {
+		t.Errorf("Expected username testuser from %s, but got: %+v, %v", filename, config, err)
+	}
+}
@@ -0,0 +1,64 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithRecoveryMode 在配置文件损坏（填充错误、完整性校验失败或无法反序列化）时，
+// 依次尝试从最新到最旧的备份读取配置，需要同时启用 WithBackup，仅对 FileBackend 生效。
+// 备份读取成功时用备份替换损坏的文件，并通过 Logger 输出警告。
+// 这只是最后的恢复手段：备份使用同样的 key 加密并经过同样的校验，key 错误时同样无法读取，不会绕过任何安全检查。
+// 恢复后最近一次保存的修改会丢失。
+func WithRecoveryMode() Option {
+	return func(c *storeConfig) {
+		c.recoveryMode = true
+	}
+}
+
+// 是否是可以通过备份恢复的损坏
+func isCorruption(err error) bool {
+	return errors.Is(err, ErrDecryptionFailed) || errors.Is(err, ErrIntegrityFailure) || errors.Is(err, ErrCorruptData)
+}
+
+// 主文件损坏时尝试从备份恢复，恢复失败时返回原来的错误。调用方需要持有锁。
+func (cs *ConfigStore[T]) recoverFromBackup(ctx context.Context, loadErr error) (T, time.Time, error) {
+	var zero T
+	fb, ok := cs.fileBackend()
+	if !ok || cs.maxBackups <= 0 {
+		return zero, time.Time{}, loadErr
+	}
+	for i := 1; i <= cs.maxBackups; i++ {
+		data, err := readFile(backupName(fb.filename, i))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		config, savedAt, err := cs.decryptConfig(ctx, data)
+		if err != nil {
+			continue
+		}
+		if err := cs.promoteBackup(fb, data); err != nil {
+			return zero, time.Time{}, errors.Join(loadErr, err)
+		}
+		cs.warn("configstore: config file is corrupt, restored from backup", "file", fb.filename, "backup", i, "error", loadErr)
+		return config, savedAt, nil
+	}
+	return zero, time.Time{}, loadErr
+}
+
+// 使用备份内容替换损坏的主文件。读取只持有读锁，多个 goroutine 可能同时恢复，使用 recoverMu 避免同时写入临时文件。
+func (cs *ConfigStore[T]) promoteBackup(fb *FileBackend, data []byte) error {
+	cs.recoverMu.Lock()
+	defer cs.recoverMu.Unlock()
+	unlock, err := cs.lockFile(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := fb.Write(data); err != nil {
+		return err
+	}
+	cs.recordModTime()
+	return cs.writeChecksum(data)
+}
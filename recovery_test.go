@@ -0,0 +1,99 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 翻转文件最后一个字节，模拟填充块中的位翻转
+func corruptFile(t *testing.T, filename string) {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data[len(data)-1] ^= 0x01
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+}
+
+func TestWithRecoveryMode(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "recovery.data")
+	key := "0123456789abcdef"
+	logger := &recordingLogger{}
+	cs, err := NewConfigStore[myConfig](filename, key, WithBackup(2), WithRecoveryMode(), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	corruptFile(t, filename)
+	// 将修改时间设置到过去，保证恢复后主文件的修改时间不同
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filename, past, past); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 没有启用恢复模式时返回错误
+	plain, err := NewConfigStore[myConfig](filename, key, WithBackup(2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := plain.LoadConfig(); !isCorruption(err) {
+		t.Fatalf("Expected a corruption error, but got: %v", err)
+	}
+
+	// 从最新的备份恢复，即上一次保存的 v1
+	config, err := cs.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Username != "v1" {
+		t.Errorf("Expected username to be v1, but got: %s", config.Username)
+	}
+	if len(logger.warns) != 1 {
+		t.Errorf("Expected one warning, but got: %v", logger.warns)
+	}
+
+	// 备份被提升为主文件
+	if config, err := plain.LoadConfig(); err != nil || config.Username != "v1" {
+		t.Errorf("Expected restored file to contain v1, but got: %+v, %v", config, err)
+	}
+	// 恢复不会被当作外部修改
+	if err := cs.SaveConfig(myConfig{Username: "v3"}); err != nil {
+		t.Errorf("Expected no error after recovery, but got: %v", err)
+	}
+}
+
+func TestWithRecoveryModeWrongKey(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "recovery.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithBackup(2))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	// key 错误时备份同样无法读取，文件保持不变
+	before, _ := os.ReadFile(filename)
+	wrong, err := NewConfigStore[myConfig](filename, "fedcba9876543210", WithBackup(2), WithRecoveryMode())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := wrong.LoadConfig(); !errors.Is(err, ErrDecryptionFailed) && !errors.Is(err, ErrCorruptData) {
+		t.Errorf("Expected a decryption error, but got: %v", err)
+	}
+	if after, _ := os.ReadFile(filename); string(after) != string(before) {
+		t.Errorf("Expected file to be unchanged")
+	}
+}
@@ -0,0 +1,52 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBackend 将配置数据保存在 Redis 的一个 key 中，读取使用 GET，写入使用 SET。
+// 加密后的数据按原样作为二进制安全的字符串保存。
+type RedisBackend struct {
+	client redis.UniversalClient
+	key    string
+	ttl    time.Duration
+}
+
+// RedisBackendOption 用于调整 RedisBackend 的默认配置
+type RedisBackendOption func(*RedisBackend)
+
+// WithTTL 设置写入 key 时的过期时间，默认为 0，表示不过期
+func WithTTL(d time.Duration) RedisBackendOption {
+	return func(b *RedisBackend) {
+		b.ttl = d
+	}
+}
+
+// NewRedisBackend 创建一个保存到 Redis 中 key 的 RedisBackend，client 可以是单机、集群或哨兵客户端
+func NewRedisBackend(client redis.UniversalClient, key string, opts ...RedisBackendOption) *RedisBackend {
+	b := &RedisBackend{client: client, key: key}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *RedisBackend) Read() ([]byte, error) {
+	data, err := b.client.Get(context.Background(), b.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (b *RedisBackend) Write(data []byte) error {
+	return b.client.Set(context.Background(), b.key, data, b.ttl).Err()
+}
+
+func (b *RedisBackend) Delete() error {
+	return b.client.Del(context.Background(), b.key).Err()
+}
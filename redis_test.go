@@ -0,0 +1,82 @@
+package configstore
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+func TestRedisBackend(t *testing.T) {
+	mr, client := newTestRedis(t)
+	backend := NewRedisBackend(client, "app:config")
+
+	// 还没有写入时返回空数据
+	if data, err := backend.Read(); err != nil || len(data) != 0 {
+		t.Errorf("Expected empty data, but got: %v, %v", data, err)
+	}
+
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(backend))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpassword"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 加密后的二进制数据按原样保存
+	stored, err := mr.Get("app:config")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data, _ := backend.Read()
+	if !bytes.Equal([]byte(stored), data) {
+		t.Error("Expected stored value to match the encrypted data")
+	}
+	if bytes.Contains(data, []byte("testpassword")) {
+		t.Error("Expected stored value not to contain the plaintext")
+	}
+
+	loadConfig, err := cs.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected %+v, but got: %+v", config, loadConfig)
+	}
+
+	if err := cs.DeleteConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if mr.Exists("app:config") {
+		t.Error("Expected key to be deleted")
+	}
+}
+
+func TestRedisBackendTTL(t *testing.T) {
+	mr, client := newTestRedis(t)
+	backend := NewRedisBackend(client, "app:config", WithTTL(time.Minute))
+	if err := backend.Write([]byte("data")); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if ttl := mr.TTL("app:config"); ttl != time.Minute {
+		t.Errorf("Expected TTL 1m, but got: %v", ttl)
+	}
+
+	// 过期后视为还没有写入
+	mr.FastForward(2 * time.Minute)
+	if data, err := backend.Read(); err != nil || len(data) != 0 {
+		t.Errorf("Expected empty data after expiry, but got: %v, %v", data, err)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package configstore
+
+import "os"
+
+// 在 POSIX 系统上 rename 是原子的，会直接替换已存在的目标文件
+func renameFile(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// 刷新目录，使 rename 产生的目录项落盘
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package configstore
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// Windows 上使用 MoveFileExW 并指定 MOVEFILE_REPLACE_EXISTING 来替换已存在的目标文件
+func renameFile(oldpath, newpath string) error {
+	from, err := windows.UTF16PtrFromString(oldpath)
+	if err != nil {
+		return err
+	}
+	to, err := windows.UTF16PtrFromString(newpath)
+	if err != nil {
+		return err
+	}
+	if err := windows.MoveFileEx(from, to, windows.MOVEFILE_REPLACE_EXISTING|windows.MOVEFILE_WRITE_THROUGH); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	return nil
+}
+
+// Windows 不支持刷新目录，MOVEFILE_WRITE_THROUGH 保证 MoveFileExW 返回前重命名已经落盘
+func syncDir(dir string) error {
+	return nil
+}
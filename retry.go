@@ -0,0 +1,120 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// BackoffPolicy 决定重试之前等待的时间，attempt 为已经失败的次数，从 1 开始
+type BackoffPolicy interface {
+	Delay(attempt int) time.Duration
+}
+
+type constantBackoff time.Duration
+
+func (b constantBackoff) Delay(int) time.Duration {
+	return time.Duration(b)
+}
+
+// ConstantBackoff 每次重试之前都等待 d
+func ConstantBackoff(d time.Duration) BackoffPolicy {
+	return constantBackoff(d)
+}
+
+type exponentialBackoff struct {
+	base, max time.Duration
+}
+
+func (b exponentialBackoff) Delay(attempt int) time.Duration {
+	delay := b.base
+	for i := 1; i < attempt && delay < b.max; i++ {
+		delay *= 2
+	}
+	return min(delay, b.max)
+}
+
+// ExponentialBackoff 第一次重试之前等待 base，之后每次等待时间加倍，最多等待 max
+func ExponentialBackoff(base, max time.Duration) BackoffPolicy {
+	return exponentialBackoff{base: base, max: max}
+}
+
+// WithRetry 在存储后端读写失败且错误可以重试时最多尝试 maxAttempts 次，每次重试之前按 backoff 等待，
+// 适用于 S3、Redis 等网络存储后端。错误链中有实现了 Retryable() bool 并返回 true 的错误时可以重试，
+// 标准库的网络错误（如连接被重置、超时）会自动被当作可以重试的错误，重试耗尽后返回的错误同样实现了 Retryable。
+// maxAttempts 小于 1 或 backoff 为 nil 时 New 返回 ErrInvalidOption。
+func WithRetry(maxAttempts int, backoff BackoffPolicy) Option {
+	return func(c *storeConfig) {
+		c.retryAttempts = maxAttempts
+		c.retryBackoff = backoff
+		c.retry = true
+	}
+}
+
+func checkRetry(cfg *storeConfig) error {
+	if cfg.retry && (cfg.retryAttempts < 1 || cfg.retryBackoff == nil) {
+		return fmt.Errorf("%w: retry requires at least 1 attempt and a backoff policy", ErrInvalidOption)
+	}
+	return nil
+}
+
+// 标准库的网络错误包装为可以重试的错误
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string   { return e.err.Error() }
+func (e *retryableError) Unwrap() error   { return e.err }
+func (e *retryableError) Retryable() bool { return true }
+
+// 网络错误包装为 retryableError，其他错误保持不变
+func wrapRetryable(err error) error {
+	var r interface{ Retryable() bool }
+	if err == nil || errors.As(err, &r) || !isNetworkError(err) {
+		return err
+	}
+	return &retryableError{err: err}
+}
+
+func isNetworkError(err error) bool {
+	for _, errno := range []syscall.Errno{syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED, syscall.EPIPE, syscall.ETIMEDOUT} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	var ne net.Error
+	return errors.Is(err, os.ErrDeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout())
+}
+
+// 错误链中有 Retryable 返回 true 的错误时可以重试
+func isRetryable(err error) bool {
+	var r interface{ Retryable() bool }
+	return errors.As(wrapRetryable(err), &r) && r.Retryable()
+}
+
+// 按 WithRetry 的设置调用 fn，ctx 被取消时停止等待
+func (cs *ConfigStore[T]) withRetry(ctx context.Context, fn func() error) error {
+	attempts := 1
+	if cs.retry {
+		attempts = cs.retryAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		err := wrapRetryable(fn())
+		if err == nil || attempt >= attempts || !isRetryable(err) {
+			return err
+		}
+		delay := cs.retryBackoff.Delay(attempt)
+		cs.warn("configstore: backend operation failed, retrying", "attempt", attempt, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, context.Cause(ctx))
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "temporary failure" }
+func (temporaryError) Retryable() bool { return true }
+
+// 前 failures 次读写返回 err 的存储后端
+type flakyBackend struct {
+	MemoryBackend
+	err      error
+	failures int
+	calls    int
+}
+
+func (b *flakyBackend) fail() error {
+	b.calls++
+	if b.calls <= b.failures {
+		return b.err
+	}
+	return nil
+}
+
+func (b *flakyBackend) Read() ([]byte, error) {
+	if err := b.fail(); err != nil {
+		return nil, err
+	}
+	return b.MemoryBackend.Read()
+}
+
+func (b *flakyBackend) Write(data []byte) error {
+	if err := b.fail(); err != nil {
+		return err
+	}
+	return b.MemoryBackend.Write(data)
+}
+
+func TestWithRetry(t *testing.T) {
+	for _, backendErr := range []error{
+		temporaryError{},
+		fmt.Errorf("write tcp: %w", syscall.ECONNRESET),
+	} {
+		backend := &flakyBackend{err: backendErr, failures: 2}
+		cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"), WithRetry(3, ConstantBackoff(time.Millisecond)))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if backend.calls != 3 {
+			t.Errorf("Expected 3 write attempts, but got: %d", backend.calls)
+		}
+
+		// 重试耗尽后返回的错误可以重试
+		backend.calls, backend.failures = 0, 3
+		_, err = cs.LoadConfigOrDefault(myConfig{})
+		if !isRetryable(err) || !errors.Is(err, backendErr) {
+			t.Errorf("Expected a retryable error, but got: %v", err)
+		}
+		if backend.calls != 3 {
+			t.Errorf("Expected 3 read attempts, but got: %d", backend.calls)
+		}
+		backend.calls, backend.failures = 0, 1
+		if config, err := cs.LoadConfigOrDefault(myConfig{}); err != nil || config.Username != "testuser" {
+			t.Errorf("Expected username testuser, but got: %+v, %v", config, err)
+		}
+	}
+}
+
+func TestWithRetryNotRetryable(t *testing.T) {
+	backend := &flakyBackend{err: errors.New("permission denied"), failures: 1}
+	cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"), WithRetry(3, ConstantBackoff(0)))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{}); err == nil || backend.calls != 1 {
+		t.Errorf("Expected a single failed attempt, but got: %d attempts, %v", backend.calls, err)
+	}
+
+	// 没有设置 WithRetry 时不重试
+	backend = &flakyBackend{err: temporaryError{}, failures: 1}
+	cs, err = New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{}); err == nil || backend.calls != 1 {
+		t.Errorf("Expected a single failed attempt, but got: %d attempts, %v", backend.calls, err)
+	}
+
+	for _, opt := range []Option{WithRetry(0, ConstantBackoff(0)), WithRetry(3, nil)} {
+		if _, err := New[myConfig](WithBackend(NewMemoryBackend()), WithKey("0123456789abcdef"), opt); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption, but got: %v", err)
+		}
+	}
+}
+
+func TestWithRetryContext(t *testing.T) {
+	backend := &flakyBackend{err: temporaryError{}, failures: 100}
+	cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"), WithRetry(100, ConstantBackoff(time.Hour)))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := cs.LoadConfigContext(ctx, myConfig{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, but got: %v", err)
+	}
+}
+
+func TestBackoffPolicy(t *testing.T) {
+	if d := ConstantBackoff(time.Second).Delay(5); d != time.Second {
+		t.Errorf("Expected 1s, but got: %v", d)
+	}
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	for attempt, expected := range map[int]time.Duration{
+		1:    100 * time.Millisecond,
+		2:    200 * time.Millisecond,
+		4:    800 * time.Millisecond,
+		5:    time.Second,
+		1000: time.Second,
+	} {
+		if d := backoff.Delay(attempt); d != expected {
+			t.Errorf("Expected attempt %d to wait %v, but got: %v", attempt, expected, d)
+		}
+	}
+}
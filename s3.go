@@ -0,0 +1,82 @@
+package configstore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// S3Backend 将配置数据保存为 S3 中的一个对象，读取使用 GetObject，写入使用 PutObject
+type S3Backend struct {
+	svc       s3iface.S3API
+	bucket    string
+	objectKey string
+	sse       bool
+	kmsKeyID  string
+}
+
+// S3BackendOption 用于调整 S3Backend 的默认配置
+type S3BackendOption func(*S3Backend)
+
+// WithSSE 写入时使用 KMS 服务端加密（aws:kms），keyID 为空时使用账户默认的 aws/s3 key
+func WithSSE(keyID string) S3BackendOption {
+	return func(b *S3Backend) {
+		b.sse = true
+		b.kmsKeyID = keyID
+	}
+}
+
+// NewS3Backend 创建一个保存到 bucket 中 objectKey 的 S3Backend
+func NewS3Backend(svc s3iface.S3API, bucket, objectKey string, opts ...S3BackendOption) *S3Backend {
+	b := &S3Backend{svc: svc, bucket: bucket, objectKey: objectKey}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *S3Backend) Read() ([]byte, error) {
+	out, err := b.svc.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.objectKey),
+	})
+	// 对象不存在时视为还没有写入
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+func (b *S3Backend) Write(data []byte) error {
+	in := &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.objectKey),
+		Body:   bytes.NewReader(data),
+	}
+	if b.sse {
+		in.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		if b.kmsKeyID != "" {
+			in.SSEKMSKeyId = aws.String(b.kmsKeyID)
+		}
+	}
+	_, err := b.svc.PutObject(in)
+	return err
+}
+
+func (b *S3Backend) Delete() error {
+	_, err := b.svc.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.objectKey),
+	})
+	return err
+}
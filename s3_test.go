@@ -0,0 +1,102 @@
+package configstore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// 在内存中保存对象的 S3API，只实现了 S3Backend 用到的方法
+type fakeS3 struct {
+	s3iface.S3API
+	mu      sync.Mutex
+	objects map[string][]byte
+	lastPut *s3.PutObjectInput
+}
+
+func (f *fakeS3) GetObject(in *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[aws.StringValue(in.Bucket)+"/"+aws.StringValue(in.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil)
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeS3) PutObject(in *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[aws.StringValue(in.Bucket)+"/"+aws.StringValue(in.Key)] = data
+	f.lastPut = in
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) DeleteObject(in *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, aws.StringValue(in.Bucket)+"/"+aws.StringValue(in.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestS3Backend(t *testing.T) {
+	svc := &fakeS3{objects: make(map[string][]byte)}
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewS3Backend(svc, "configs", "app/config.data")))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 对象不存在时返回默认配置
+	defaultConfig := myConfig{Username: "default"}
+	config, err := cs.LoadConfigOrDefault(defaultConfig)
+	if err != nil || config != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v, %v", config, err)
+	}
+
+	config = myConfig{Username: "testuser", Password: "testpassword"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if svc.lastPut.ServerSideEncryption != nil {
+		t.Errorf("Expected no server-side encryption, but got: %s", aws.StringValue(svc.lastPut.ServerSideEncryption))
+	}
+	loadConfig, err := cs.LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loadConfig != config {
+		t.Errorf("Expected %+v, but got: %+v", config, loadConfig)
+	}
+
+	if err := cs.DeleteConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+}
+
+func TestS3BackendSSE(t *testing.T) {
+	svc := &fakeS3{objects: make(map[string][]byte)}
+	backend := NewS3Backend(svc, "configs", "app/config.data", WithSSE("alias/configstore"))
+	if err := backend.Write([]byte("data")); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if sse := aws.StringValue(svc.lastPut.ServerSideEncryption); sse != s3.ServerSideEncryptionAwsKms {
+		t.Errorf("Expected aws:kms, but got: %s", sse)
+	}
+	if keyID := aws.StringValue(svc.lastPut.SSEKMSKeyId); keyID != "alias/configstore" {
+		t.Errorf("Expected KMS key alias/configstore, but got: %s", keyID)
+	}
+}
@@ -0,0 +1,271 @@
+package configstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// 生成的 JSON Schema 使用的版本
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// GenerateSchema 通过反射为 T 的 JSON 表示生成 JSON Schema，可以传给 WithJSONSchemaValidation。
+// 属性名遵循 json 标签，description 标签作为属性的说明。validate 标签遵循 go-playground/validator 的写法，
+// 支持 required、min、max、len、gt、gte、lt、lte、oneof、email 和 url：字符串的 min/max 限制长度，
+// 数字限制取值，切片和 map 限制元素个数；字符串的 required 同时要求不能为空。其他规则会被忽略。
+// nil 的指针、切片和 map 序列化为 null，因此这些字段也允许 null。
+func GenerateSchema[T any]() ([]byte, error) {
+	schema := (&schemaGenerator{seen: make(map[reflect.Type]bool)}).typeSchema(reflect.TypeFor[T]())
+	schema["$schema"] = jsonSchemaDraft
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// WithJSONSchemaValidation 在每次保存前和读取后使用 JSON Schema 校验配置的 JSON 表示，与 WithFormat 无关。
+// 校验失败的处理与 WithValidator 相同，错误可以用 errors.Is 与 ErrInvalidConfig 比较。
+// schema 不合法时 New 返回 ErrInvalidOption。
+func WithJSONSchemaValidation(schema []byte) Option {
+	return func(c *storeConfig) {
+		c.jsonSchema = schema
+	}
+}
+
+// 编译 WithJSONSchemaValidation 设置的 schema，没有设置时返回 nil
+func compileJSONSchema(schema []byte) (*jsonschema.Schema, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("%w: json schema: %w", ErrInvalidOption, err)
+	}
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	if err := c.AddResource("config.schema.json", doc); err != nil {
+		return nil, fmt.Errorf("%w: json schema: %w", ErrInvalidOption, err)
+	}
+	compiled, err := c.Compile("config.schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("%w: json schema: %w", ErrInvalidOption, err)
+	}
+	return compiled, nil
+}
+
+// 使用 JSON Schema 校验配置，没有设置 schema 时直接返回 nil
+func (cs *ConfigStore[T]) validateSchema(config T) error {
+	if cs.schema == nil {
+		return nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if err := cs.schema.Validate(instance); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+type schemaGenerator struct {
+	// 正在生成的结构体类型，用于处理递归类型
+	seen map[reflect.Type]bool
+}
+
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]any {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+	schema := g.valueSchema(t)
+	if nullable || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		if typ, ok := schema["type"].(string); ok {
+			schema["type"] = []string{typ, "null"}
+		}
+	}
+	return schema
+}
+
+func (g *schemaGenerator) valueSchema(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// 自定义了 JSON 表示的类型无法推断结构
+		return map[string]any{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// []byte 序列化为 base64 字符串
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		schema := map[string]any{"type": "array", "items": g.typeSchema(t.Elem())}
+		if t.Kind() == reflect.Array {
+			schema["minItems"], schema["maxItems"] = t.Len(), t.Len()
+		}
+		return schema
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	if g.seen[t] {
+		return map[string]any{"type": "object"}
+	}
+	g.seen[t] = true
+	defer delete(g.seen, t)
+
+	properties := make(map[string]any)
+	var required []string
+	g.addFields(t, properties, &required)
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// 将 t 的字段加入 properties，没有 json 标签的嵌入结构体与 encoding/json 一样展开
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(ft, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := g.typeSchema(field.Type)
+		if description := field.Tag.Get("description"); description != "" {
+			schema["description"] = description
+		}
+		if applyValidateTag(schema, field.Type, field.Tag.Get("validate")) {
+			*required = append(*required, name)
+		}
+		properties[name] = schema
+	}
+}
+
+// 将 validate 标签中的规则转换为 schema 中的约束，返回字段是否必填
+func applyValidateTag(schema map[string]any, t reflect.Type, tag string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var minKey, maxKey string
+	switch t.Kind() {
+	case reflect.String:
+		minKey, maxKey = "minLength", "maxLength"
+	case reflect.Slice, reflect.Array:
+		minKey, maxKey = "minItems", "maxItems"
+	case reflect.Map:
+		minKey, maxKey = "minProperties", "maxProperties"
+	default:
+		minKey, maxKey = "minimum", "maximum"
+	}
+	numeric := minKey == "minimum"
+
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+			if t.Kind() == reflect.String {
+				if _, ok := schema["minLength"]; !ok {
+					schema["minLength"] = 1
+				}
+			}
+		case "min":
+			setNumber(schema, minKey, param, numeric)
+		case "max":
+			setNumber(schema, maxKey, param, numeric)
+		case "len":
+			if numeric {
+				setNumber(schema, "const", param, true)
+			} else {
+				setNumber(schema, minKey, param, false)
+				setNumber(schema, maxKey, param, false)
+			}
+		case "gte":
+			setNumber(schema, minKey, param, numeric)
+		case "lte":
+			setNumber(schema, maxKey, param, numeric)
+		case "gt":
+			if numeric {
+				setNumber(schema, "exclusiveMinimum", param, true)
+			}
+		case "lt":
+			if numeric {
+				setNumber(schema, "exclusiveMaximum", param, true)
+			}
+		case "oneof":
+			var values []any
+			for _, v := range strings.Fields(param) {
+				if n, err := strconv.ParseFloat(v, 64); err == nil && numeric {
+					values = append(values, n)
+				} else {
+					values = append(values, v)
+				}
+			}
+			schema["enum"] = values
+		case "email":
+			schema["format"] = "email"
+		case "url":
+			schema["format"] = "uri"
+		}
+	}
+	return required
+}
+
+// 设置数值约束，长度类的约束只接受非负整数，无法解析的参数会被忽略
+func setNumber(schema map[string]any, key, param string, numeric bool) {
+	if numeric {
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			schema[key] = n
+		}
+		return
+	}
+	if n, err := strconv.Atoi(param); err == nil && n >= 0 {
+		schema[key] = n
+	}
+}
@@ -0,0 +1,112 @@
+package configstore
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaConfig struct {
+	Name     string            `json:"name" validate:"required,max=32" description:"service name"`
+	Port     int               `json:"port" validate:"min=1,max=65535"`
+	Ratio    float64           `json:"ratio,omitempty" validate:"gte=0,lt=1"`
+	Mode     string            `json:"mode,omitempty" validate:"oneof=dev prod"`
+	Admin    string            `json:"admin,omitempty" validate:"email"`
+	Hosts    []string          `json:"hosts" validate:"max=3"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Timeout  *int              `json:"timeout,omitempty"`
+	Created  time.Time         `json:"created"`
+	Internal string            `json:"-"`
+	schemaBase
+}
+
+type schemaBase struct {
+	Version uint `json:"version"`
+}
+
+func TestGenerateSchema(t *testing.T) {
+	data, err := GenerateSchema[schemaConfig]()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Expected valid JSON, but got: %v", err)
+	}
+	if schema["$schema"] != jsonSchemaDraft || schema["type"] != "object" {
+		t.Errorf("Expected an object schema, but got: %v", schema)
+	}
+	if !reflect.DeepEqual(schema["required"], []any{"name"}) {
+		t.Errorf("Expected name to be required, but got: %v", schema["required"])
+	}
+
+	properties := schema["properties"].(map[string]any)
+	expected := map[string]any{
+		"name":    map[string]any{"type": "string", "minLength": 1.0, "maxLength": 32.0, "description": "service name"},
+		"port":    map[string]any{"type": "integer", "minimum": 1.0, "maximum": 65535.0},
+		"ratio":   map[string]any{"type": "number", "minimum": 0.0, "exclusiveMaximum": 1.0},
+		"mode":    map[string]any{"type": "string", "enum": []any{"dev", "prod"}},
+		"admin":   map[string]any{"type": "string", "format": "email"},
+		"hosts":   map[string]any{"type": []any{"array", "null"}, "items": map[string]any{"type": "string"}, "maxItems": 3.0},
+		"labels":  map[string]any{"type": []any{"object", "null"}, "additionalProperties": map[string]any{"type": "string"}},
+		"timeout": map[string]any{"type": []any{"integer", "null"}},
+		"created": map[string]any{"type": "string", "format": "date-time"},
+		"version": map[string]any{"type": "integer", "minimum": 0.0},
+	}
+	if !reflect.DeepEqual(properties, expected) {
+		got, _ := json.Marshal(properties)
+		want, _ := json.Marshal(expected)
+		t.Errorf("Expected properties %s, but got: %s", want, got)
+	}
+}
+
+func TestWithJSONSchemaValidation(t *testing.T) {
+	schema, err := GenerateSchema[schemaConfig]()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	filename := filepath.Join(t.TempDir(), "schema.data")
+	key := "0123456789abcdef"
+	cs, err := New[schemaConfig](WithFile(filename), WithKey(key), WithJSONSchemaValidation(schema))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	valid := schemaConfig{Name: "api", Port: 8080, Mode: "prod", Admin: "ops@example.com"}
+	if err := cs.SaveConfig(valid); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+
+	for name, config := range map[string]schemaConfig{
+		"empty name":    {Port: 8080},
+		"negative port": {Name: "api", Port: -1},
+		"unknown mode":  {Name: "api", Port: 8080, Mode: "test"},
+		"invalid email": {Name: "api", Port: 8080, Admin: "not an email"},
+		"too many":      {Name: "api", Port: 8080, Hosts: []string{"a", "b", "c", "d"}},
+	} {
+		if err := cs.SaveConfig(config); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected ErrInvalidConfig, but got: %v", name, err)
+		}
+	}
+
+	// 读取时同样校验，例如没有设置 schema 的 ConfigStore 写入的配置
+	plain, err := New[schemaConfig](WithFile(filename), WithKey(key))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := plain.SaveConfig(schemaConfig{Name: "api"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig on load, but got: %v", err)
+	}
+
+	if _, err := New[schemaConfig](WithFile(filename), WithKey(key), WithJSONSchemaValidation([]byte(`{"type":`))); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for an invalid schema, but got: %v", err)
+	}
+}
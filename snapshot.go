@@ -0,0 +1,131 @@
+package configstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 自动快照文件名中的时间格式，按文件名排序即按时间排序
+const snapshotTimeFormat = "20060102T150405.000000000Z"
+
+// Snapshot 是某一时刻保存的配置，用于审计
+type Snapshot[T any] struct {
+	Data T
+	// SavedAt 是创建快照的时间
+	SavedAt time.Time
+	// Checksum 是 Data 经过 JSON 序列化后的 SHA-256
+	Checksum [32]byte
+}
+
+// WithAutoSnapshot 在每次 SaveConfig 成功后将同样的文件内容写入 dir 中的快照文件，文件名为保存时间（UTC），
+// 可以通过 LoadSnapshot 读取。启用后文件头部会记录保存时间。dir 不存在时自动创建。
+func WithAutoSnapshot(dir string) Option {
+	return func(c *storeConfig) {
+		c.snapshotDir = dir
+	}
+}
+
+// TakeSnapshot 读取当前保存的配置作为快照，不会应用环境变量、调用回调或更新缓存
+func (cs *ConfigStore[T]) TakeSnapshot() (Snapshot[T], error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	snapshot, err := cs.takeSnapshot()
+	return snapshot, cs.wrapError("snapshot", err)
+}
+
+// SaveSnapshot 读取当前保存的配置，使用当前的 key 加密后写入 path
+func (cs *ConfigStore[T]) SaveSnapshot(path string) error {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.wrapError("snapshot", cs.saveSnapshot(path))
+}
+
+// LoadSnapshot 读取 SaveSnapshot 或 WithAutoSnapshot 写入的快照文件，快照不受 WithMaxAge 的限制
+func (cs *ConfigStore[T]) LoadSnapshot(path string) (Snapshot[T], error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	snapshot, err := cs.loadSnapshot(path)
+	return snapshot, cs.wrapError("snapshot", err)
+}
+
+func (cs *ConfigStore[T]) takeSnapshot() (Snapshot[T], error) {
+	config, _, err := cs.loadConfig(context.Background())
+	if err != nil {
+		return Snapshot[T]{}, err
+	}
+	return newSnapshot(config, time.Now())
+}
+
+func (cs *ConfigStore[T]) saveSnapshot(path string) error {
+	snapshot, err := cs.takeSnapshot()
+	if err != nil {
+		return err
+	}
+	configData, err := cs.codec.Marshal(snapshot.Data)
+	if err != nil {
+		return err
+	}
+	secret, err := cs.secret(context.Background())
+	if err != nil {
+		return err
+	}
+	data, err := cs.seal(secret, configData, cs.dataVersion, snapshot.SavedAt)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data, cs.fileMode, cs.sync)
+}
+
+func (cs *ConfigStore[T]) loadSnapshot(path string) (Snapshot[T], error) {
+	if cs.closed {
+		return Snapshot[T]{}, ErrStoreClosed
+	}
+	data, err := readFile(path)
+	if os.IsNotExist(err) {
+		return Snapshot[T]{}, fmt.Errorf("snapshot %s: %w", path, ErrFileNotFound)
+	}
+	if err != nil {
+		return Snapshot[T]{}, err
+	}
+	if len(data) == 0 {
+		return Snapshot[T]{}, fmt.Errorf("%w: snapshot %s is empty", ErrCorruptData, path)
+	}
+	secret, err := cs.secret(context.Background())
+	if err != nil {
+		return Snapshot[T]{}, err
+	}
+	config, header, err := cs.decodeFile(secret, data)
+	if err != nil {
+		return Snapshot[T]{}, err
+	}
+	return newSnapshot(config, header.savedTime())
+}
+
+// 保存成功后写入自动快照，data 为已经写入存储后端的文件内容，调用方需要持有写锁
+func (cs *ConfigStore[T]) autoSnapshot(data []byte) error {
+	if cs.snapshotDir == "" {
+		return nil
+	}
+	header, _, err := parseFileHeader(data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cs.snapshotDir, 0700); err != nil {
+		return err
+	}
+	name := header.savedTime().UTC().Format(snapshotTimeFormat) + ".snapshot"
+	return writeFile(filepath.Join(cs.snapshotDir, name), data, cs.fileMode, cs.sync)
+}
+
+func newSnapshot[T any](config T, savedAt time.Time) (Snapshot[T], error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return Snapshot[T]{}, err
+	}
+	return Snapshot[T]{Data: config, SavedAt: savedAt, Checksum: sha256.Sum256(data)}, nil
+}
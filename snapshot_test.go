@@ -0,0 +1,87 @@
+package configstore
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	cs, err := New[myConfig](WithFile(filepath.Join(dir, "config.data")), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.TakeSnapshot(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected ErrNoConfig, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser", Password: "testpass"}
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	snapshot, err := cs.TakeSnapshot()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	data, _ := json.Marshal(config)
+	if snapshot.Data != config || snapshot.Checksum != sha256.Sum256(data) {
+		t.Errorf("Expected snapshot of %+v, but got: %+v", config, snapshot)
+	}
+
+	path := filepath.Join(dir, "audit.snapshot")
+	if err := cs.SaveSnapshot(path); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 快照文件是加密的
+	raw, _ := os.ReadFile(path)
+	if json.Valid(raw) {
+		t.Errorf("Expected snapshot file to be encrypted")
+	}
+	loaded, err := cs.LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded.Data != config || loaded.Checksum != snapshot.Checksum || loaded.SavedAt.IsZero() {
+		t.Errorf("Expected loaded snapshot to match, but got: %+v", loaded)
+	}
+
+	if _, err := cs.LoadSnapshot(filepath.Join(dir, "missing.snapshot")); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound, but got: %v", err)
+	}
+}
+
+func TestWithAutoSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	snapshots := filepath.Join(dir, "snapshots")
+	cs, err := New[myConfig](WithFile(filepath.Join(dir, "config.data")), WithKey("0123456789abcdef"), WithAutoSnapshot(snapshots))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	before := time.Now()
+	for _, name := range []string{"v1", "v2"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(snapshots)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 snapshots, but got: %d", len(entries))
+	}
+	// 文件名按时间排序，最后一个是最新的快照
+	latest, err := cs.LoadSnapshot(filepath.Join(snapshots, entries[1].Name()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if latest.Data.Username != "v2" || latest.SavedAt.Before(before) {
+		t.Errorf("Expected latest snapshot to be v2, but got: %+v", latest)
+	}
+}
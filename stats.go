@@ -0,0 +1,91 @@
+package configstore
+
+import (
+	"os"
+	"time"
+)
+
+// ConfigStats 是已保存配置的元数据，由 Stats 返回
+type ConfigStats struct {
+	// Exists 表示配置文件是否存在，其他存储后端中表示是否保存过数据
+	Exists bool
+	// FileSize 是加密后的文件大小（字节）
+	FileSize int64
+	// ModTime 是文件的最后修改时间，只有 FileBackend 提供
+	ModTime time.Time
+	// FormatVersion 是文件格式的版本，没有头部的旧文件为 0
+	FormatVersion int
+	// CipherMode 是文件头部记录的加密模式，没有头部的旧文件为当前配置的加密模式
+	CipherMode CipherMode
+	// DataVersion 是文件头部记录的数据版本，没有记录时为 0
+	DataVersion int
+	// SavedAt 是文件头部记录的保存时间，只有启用 WithMaxAge 或 WithAutoSnapshot 时记录，没有记录时为零值
+	SavedAt time.Time
+	// Circuit 是 WithCircuitBreaker 设置的断路器的状态，断路器打开时 Stats 不访问存储后端，其他字段为零值
+	Circuit CircuitState
+}
+
+// Stats 返回已保存配置的元数据，不需要解密。文件不存在时 Exists 为 false，其他字段为零值，不返回错误。
+func (cs *ConfigStore[T]) Stats() (ConfigStats, error) {
+	ctx, cancel := cs.defaultContext()
+	defer cancel()
+	if err := cs.mu.RLockContext(ctx); err != nil {
+		return ConfigStats{}, cs.wrapError("stats", err)
+	}
+	defer cs.mu.RUnlock()
+
+	stats, err := cs.stats()
+	return stats, cs.wrapError("stats", err)
+}
+
+func (cs *ConfigStore[T]) stats() (ConfigStats, error) {
+	var stats ConfigStats
+	if cs.closed {
+		return stats, ErrStoreClosed
+	}
+	if stats.Circuit = cs.breaker.state(); stats.Circuit == CircuitOpen {
+		return stats, nil
+	}
+	unlock, err := cs.lockFile(false)
+	if err != nil {
+		return stats, err
+	}
+	defer unlock()
+
+	fb, isFile := cs.fileBackend()
+	if isFile {
+		info, err := os.Stat(fb.filename)
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+		stats.Exists = true
+		stats.FileSize = info.Size()
+		stats.ModTime = info.ModTime()
+	}
+
+	data, err := cs.backend.Read()
+	if err != nil {
+		return ConfigStats{Circuit: stats.Circuit}, err
+	}
+	if !isFile {
+		stats.Exists = len(data) > 0
+		stats.FileSize = int64(len(data))
+	}
+	header, _, err := parseFileHeader(data)
+	if err != nil {
+		return ConfigStats{Circuit: stats.Circuit}, err
+	}
+	if len(data) > 0 {
+		stats.FormatVersion = int(header.version)
+		stats.CipherMode = header.cipherMode
+		if header.version == 0 {
+			stats.CipherMode = cs.cipherMode
+		}
+	}
+	stats.DataVersion = int(header.dataVersion)
+	stats.SavedAt = header.savedTime()
+	return stats, nil
+}
@@ -0,0 +1,62 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "stats.data")
+	// 使用 WithBackend 时不会预先创建文件
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewFileBackend(filename)), WithMigration(3))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	stats, err := cs.Stats()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if stats != (ConfigStats{}) {
+		t.Errorf("Expected zero stats for a missing file, but got: %+v", stats)
+	}
+
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	stats, err = cs.Stats()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !stats.Exists || stats.FileSize != info.Size() || !stats.ModTime.Equal(info.ModTime()) {
+		t.Errorf("Expected stats to match the file, but got: %+v", stats)
+	}
+	if stats.DataVersion != 3 {
+		t.Errorf("Expected data version 3, but got: %d", stats.DataVersion)
+	}
+	if stats.FormatVersion != formatVersion || stats.CipherMode != CipherModeCBC {
+		t.Errorf("Expected format version %d and CBC, but got: %d, %v", formatVersion, stats.FormatVersion, stats.CipherMode)
+	}
+}
+
+func TestStatsMemoryBackend(t *testing.T) {
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if stats, err := cs.Stats(); err != nil || stats.Exists {
+		t.Errorf("Expected no saved config, but got: %+v, %v", stats, err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	stats, err := cs.Stats()
+	if err != nil || !stats.Exists || stats.FileSize == 0 || stats.DataVersion != 0 {
+		t.Errorf("Expected saved config without data version, but got: %+v, %v", stats, err)
+	}
+}
@@ -0,0 +1,55 @@
+package configstore
+
+import "sync"
+
+// Store 是 ConfigStore 的最小接口，下游代码可以依赖 Store，在测试中注入 NewMemoryStore 或 NewErrStore
+type Store[T any] interface {
+	LoadConfigOrDefault(defaultConfig T) (T, error)
+	SaveConfig(config T) error
+}
+
+var _ Store[struct{}] = (*ConfigStore[struct{}])(nil)
+
+// MemoryStore 是保存在内存中的 Store，可以并发使用
+type MemoryStore[T any] struct {
+	mu     sync.RWMutex
+	config T
+}
+
+// NewMemoryStore 创建一个以 initial 作为已保存配置的 MemoryStore
+func NewMemoryStore[T any](initial T) Store[T] {
+	return &MemoryStore[T]{config: initial}
+}
+
+// LoadConfigOrDefault 返回最近一次保存的配置，MemoryStore 总是有已保存的配置，因此不会返回 defaultConfig
+func (s *MemoryStore[T]) LoadConfigOrDefault(defaultConfig T) (T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config, nil
+}
+
+func (s *MemoryStore[T]) SaveConfig(config T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+	return nil
+}
+
+// ErrStore 总是返回指定错误的 Store，用于测试错误处理
+type ErrStore[T any] struct {
+	loadErr error
+	saveErr error
+}
+
+// NewErrStore 创建一个 ErrStore，LoadConfigOrDefault 返回 defaultConfig 和 loadErr，SaveConfig 返回 saveErr
+func NewErrStore[T any](loadErr, saveErr error) Store[T] {
+	return &ErrStore[T]{loadErr: loadErr, saveErr: saveErr}
+}
+
+func (s *ErrStore[T]) LoadConfigOrDefault(defaultConfig T) (T, error) {
+	return defaultConfig, s.loadErr
+}
+
+func (s *ErrStore[T]) SaveConfig(config T) error {
+	return s.saveErr
+}
@@ -0,0 +1,56 @@
+package configstore
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	var store Store[myConfig] = NewMemoryStore(myConfig{Username: "initial"})
+	config, err := store.LoadConfigOrDefault(myConfig{Username: "default"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Username != "initial" {
+		t.Errorf("Expected username initial, but got: %s", config.Username)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+			if _, err := store.LoadConfigOrDefault(myConfig{}); err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	config, _ = store.LoadConfigOrDefault(myConfig{})
+	if config.Username != "testuser" {
+		t.Errorf("Expected username testuser, but got: %s", config.Username)
+	}
+}
+
+func TestErrStore(t *testing.T) {
+	loadErr := errors.New("load failed")
+	saveErr := errors.New("save failed")
+	store := NewErrStore[myConfig](loadErr, saveErr)
+
+	defaultConfig := myConfig{Username: "default"}
+	config, err := store.LoadConfigOrDefault(defaultConfig)
+	if !errors.Is(err, loadErr) {
+		t.Errorf("Expected load error, but got: %v", err)
+	}
+	if config != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v", config)
+	}
+	if err := store.SaveConfig(myConfig{}); !errors.Is(err, saveErr) {
+		t.Errorf("Expected save error, but got: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package configstore
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// EncryptConfig 使用与 SaveConfig 相同的方式序列化并加密 config，将结果写入 w，而不是存储后端。
+// 写入 w 的内容可以由 DecryptConfig 或 LoadConfig 读取。
+func (cs *ConfigStore[T]) EncryptConfig(config T, w io.Writer) error {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.wrapError("encrypt", cs.encryptConfig(context.Background(), config, w))
+}
+
+// DecryptConfig 从 r 中读取 EncryptConfig 写入的内容并解密，r 中没有数据时返回 defaultConfig
+func (cs *ConfigStore[T]) DecryptConfig(r io.Reader, defaultConfig T) (T, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return defaultConfig, cs.wrapError("decrypt", err)
+	}
+	if len(data) == 0 {
+		return defaultConfig, nil
+	}
+
+	cs.mu.RLock()
+	config, _, err := cs.decryptConfig(context.Background(), data)
+	cs.mu.RUnlock()
+	if err == nil {
+		config, err = cs.overrideEnv(config)
+	}
+	config, err = orDefault(config, err, defaultConfig)
+	return config, cs.wrapError("decrypt", err)
+}
+
+// 校验、序列化并加密配置，将文件内容写入 w，调用方需要持有锁
+func (cs *ConfigStore[T]) encryptConfig(ctx context.Context, config T, w io.Writer) error {
+	if cs.closed {
+		return ErrStoreClosed
+	}
+	if err := cs.validate(config); err != nil {
+		return err
+	}
+	// 将配置转换为字节切片
+	configData, err := cs.codec.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	// 加密配置数据
+	secret, err := cs.secret(ctx)
+	if err != nil {
+		return err
+	}
+	var savedAt time.Time
+	if cs.ttl > 0 || cs.snapshotDir != "" {
+		savedAt = time.Now()
+	}
+	encryptedData, err := cs.seal(secret, configData, cs.dataVersion, savedAt)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(encryptedData)
+	return err
+}
+
+// 解密并校验文件内容，同时返回保存时间，调用方需要持有锁
+func (cs *ConfigStore[T]) decryptConfig(ctx context.Context, data []byte) (T, time.Time, error) {
+	if cs.closed {
+		var zero T
+		return zero, time.Time{}, ErrStoreClosed
+	}
+	secret, err := cs.secret(ctx)
+	if err != nil {
+		var zero T
+		return zero, time.Time{}, err
+	}
+	config, savedAt, err := cs.decode(secret, data)
+	if err != nil {
+		return config, savedAt, err
+	}
+	return config, savedAt, cs.validate(config)
+}
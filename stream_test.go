@@ -0,0 +1,69 @@
+package configstore
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecryptConfig(t *testing.T) {
+	backend := NewMemoryBackend()
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(backend))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := cs.EncryptConfig(myConfig{Username: "testuser", Password: "testpassword"}, &buf); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("testpassword")) {
+		t.Error("Expected encrypted output not to contain the plaintext")
+	}
+	// EncryptConfig 不写入存储后端
+	if data, _ := backend.Read(); len(data) != 0 {
+		t.Errorf("Expected nothing to be written to the backend, but got %d bytes", len(data))
+	}
+
+	// 写入 w 的内容与 SaveConfig 保存的格式相同
+	if err := backend.Write(buf.Bytes()); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := cs.LoadConfig()
+	if err != nil || config.Username != "testuser" {
+		t.Errorf("Expected username testuser, but got: %+v, %v", config, err)
+	}
+
+	config, err = cs.DecryptConfig(&buf, myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if config.Username != "testuser" || config.Password != "testpassword" {
+		t.Errorf("Expected decrypted config to match, but got: %+v", config)
+	}
+}
+
+func TestDecryptConfigEmpty(t *testing.T) {
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := cs.DecryptConfig(strings.NewReader(""), myConfig{Username: "default"})
+	if err != nil || config.Username != "default" {
+		t.Errorf("Expected default config, but got: %+v, %v", config, err)
+	}
+}
+
+func TestDecryptConfigWrongKey(t *testing.T) {
+	cs1, _ := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()), WithCipherMode(CipherModeGCM))
+	cs2, _ := NewConfigStore[myConfig]("", "fedcba9876543210", WithBackend(NewMemoryBackend()), WithCipherMode(CipherModeGCM))
+
+	var buf bytes.Buffer
+	if err := cs1.EncryptConfig(myConfig{Username: "testuser"}, &buf); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs2.DecryptConfig(&buf, myConfig{}); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed, but got: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package configstore
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Table 将 []T 类型的配置作为一张表使用，每个操作读取完整的切片、修改后在同一次加锁中保存
+type Table[T any] struct {
+	store      *ConfigStore[[]T]
+	primaryKey func(T) string
+}
+
+// TableOption 用于配置 Table
+type TableOption[T any] func(*Table[T])
+
+// PrimaryKey 设置行的主键，Insert 时已经存在相同主键的行则返回 ErrDuplicateKey
+func PrimaryKey[T any](fn func(T) string) TableOption[T] {
+	return func(t *Table[T]) {
+		t.primaryKey = fn
+	}
+}
+
+// 用于在 UpdateConfig 的回调中表示不需要保存
+var errNoChange = errors.New("no change")
+
+// NewTable 创建一个使用 store 保存所有行的 Table
+func NewTable[T any](store *ConfigStore[[]T], opts ...TableOption[T]) *Table[T] {
+	t := &Table[T]{store: store}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Store 返回底层的 ConfigStore
+func (t *Table[T]) Store() *ConfigStore[[]T] {
+	return t.store
+}
+
+// Insert 在表的末尾添加 row
+func (t *Table[T]) Insert(row T) error {
+	return t.update(func(rows []T) ([]T, error) {
+		if t.primaryKey != nil {
+			key := t.primaryKey(row)
+			if slices.ContainsFunc(rows, func(r T) bool { return t.primaryKey(r) == key }) {
+				return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, key)
+			}
+		}
+		// 缓存中的切片与调用方共享，复制后再修改，保存失败时缓存不受影响
+		return append(slices.Clip(rows), row), nil
+	})
+}
+
+// Find 返回所有满足 predicate 的行，还没有保存过配置时返回空结果
+func (t *Table[T]) Find(predicate func(T) bool) ([]T, error) {
+	rows, err := t.store.LoadConfigOrDefault(nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []T
+	for _, row := range rows {
+		if predicate(row) {
+			result = append(result, row)
+		}
+	}
+	return result, nil
+}
+
+// Update 对所有满足 predicate 的行调用 update，没有满足条件的行时不写入
+func (t *Table[T]) Update(predicate func(T) bool, update func(*T)) error {
+	return t.update(func(rows []T) ([]T, error) {
+		rows = slices.Clone(rows)
+		changed := false
+		for i := range rows {
+			if predicate(rows[i]) {
+				update(&rows[i])
+				changed = true
+			}
+		}
+		if !changed {
+			return nil, errNoChange
+		}
+		return rows, nil
+	})
+}
+
+// Delete 删除所有满足 predicate 的行并返回删除的行数，没有满足条件的行时不写入
+func (t *Table[T]) Delete(predicate func(T) bool) (int, error) {
+	var deleted int
+	err := t.update(func(rows []T) ([]T, error) {
+		remaining := slices.DeleteFunc(slices.Clone(rows), predicate)
+		deleted = len(rows) - len(remaining)
+		if deleted == 0 {
+			return nil, errNoChange
+		}
+		return remaining, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// 通过 UpdateConfig 读取、修改并保存所有行，fn 返回 errNoChange 时不写入
+func (t *Table[T]) update(fn func(rows []T) ([]T, error)) error {
+	err := t.store.UpdateConfig(fn)
+	if errors.Is(err, errNoChange) {
+		return nil
+	}
+	return err
+}
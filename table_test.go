@@ -0,0 +1,144 @@
+package configstore
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type userRow struct {
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+	Admin bool   `json:"admin"`
+}
+
+func newUserTable(t *testing.T, opts ...Option) *Table[userRow] {
+	t.Helper()
+	store, err := New[[]userRow](append([]Option{WithFile(filepath.Join(t.TempDir(), "users.data")), WithKey("0123456789abcdef")}, opts...)...)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	return NewTable(store, PrimaryKey(func(u userRow) string { return u.Name }))
+}
+
+func TestTable(t *testing.T) {
+	for _, cache := range []bool{false, true} {
+		var opts []Option
+		if cache {
+			opts = append(opts, WithCache())
+		}
+		table := newUserTable(t, opts...)
+
+		// 还没有保存过配置时查询结果为空
+		if rows, err := table.Find(func(userRow) bool { return true }); err != nil || len(rows) != 0 {
+			t.Errorf("Expected no rows, but got: %v, %v", rows, err)
+		}
+		for _, row := range []userRow{{Name: "alice", Role: "dev"}, {Name: "bob", Role: "ops"}, {Name: "carol", Role: "dev"}} {
+			if err := table.Insert(row); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+		}
+		if err := table.Insert(userRow{Name: "alice"}); !errors.Is(err, ErrDuplicateKey) {
+			t.Errorf("Expected ErrDuplicateKey, but got: %v", err)
+		}
+
+		devs, err := table.Find(func(u userRow) bool { return u.Role == "dev" })
+		if err != nil || len(devs) != 2 || devs[0].Name != "alice" || devs[1].Name != "carol" {
+			t.Errorf("Expected alice and carol, but got: %v, %v", devs, err)
+		}
+
+		if err := table.Update(func(u userRow) bool { return u.Role == "dev" }, func(u *userRow) { u.Admin = true }); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		admins, err := table.Find(func(u userRow) bool { return u.Admin })
+		if err != nil || len(admins) != 2 {
+			t.Errorf("Expected 2 admins, but got: %v, %v", admins, err)
+		}
+		// 修改查询结果不影响表中的数据
+		admins[0].Role = "changed"
+		if rows, _ := table.Find(func(u userRow) bool { return u.Role == "changed" }); len(rows) != 0 {
+			t.Errorf("Expected Find to return a copy, but got: %v", rows)
+		}
+
+		deleted, err := table.Delete(func(u userRow) bool { return u.Admin })
+		if err != nil || deleted != 2 {
+			t.Errorf("Expected 2 rows to be deleted, but got: %d, %v", deleted, err)
+		}
+		if deleted, err := table.Delete(func(u userRow) bool { return u.Admin }); err != nil || deleted != 0 {
+			t.Errorf("Expected no rows to be deleted, but got: %d, %v", deleted, err)
+		}
+
+		// 保存的数据中只剩下 bob
+		rows, err := table.Store().LoadConfig()
+		if err != nil || len(rows) != 1 || rows[0] != (userRow{Name: "bob", Role: "ops"}) {
+			t.Errorf("Expected only bob to remain, but got: %v, %v", rows, err)
+		}
+	}
+}
+
+func TestTableConcurrent(t *testing.T) {
+	table := newUserTable(t, WithCache())
+	const n = 20
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	duplicates := 0
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := table.Insert(userRow{Name: "user" + strconv.Itoa(i)}); err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+		}()
+		// 同一个主键只有一次插入成功
+		go func() {
+			defer wg.Done()
+			err := table.Insert(userRow{Name: "shared"})
+			if errors.Is(err, ErrDuplicateKey) {
+				mu.Lock()
+				duplicates++
+				mu.Unlock()
+			} else if err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if duplicates != n-1 {
+		t.Errorf("Expected %d duplicate inserts, but got: %d", n-1, duplicates)
+	}
+	rows, err := table.Find(func(userRow) bool { return true })
+	if err != nil || len(rows) != n+1 {
+		t.Fatalf("Expected %d rows, but got: %d, %v", n+1, len(rows), err)
+	}
+
+	// 并发地修改和删除不同的行，所有修改都被保留
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := "user" + strconv.Itoa(i)
+			if i%2 == 0 {
+				if _, err := table.Delete(func(u userRow) bool { return u.Name == name }); err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+				}
+				return
+			}
+			if err := table.Update(func(u userRow) bool { return u.Name == name }, func(u *userRow) { u.Admin = true }); err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	admins, err := table.Find(func(u userRow) bool { return u.Admin })
+	if err != nil || len(admins) != n/2 {
+		t.Errorf("Expected %d admins, but got: %d, %v", n/2, len(admins), err)
+	}
+	rows, err = table.Find(func(userRow) bool { return true })
+	if err != nil || len(rows) != n/2+1 {
+		t.Errorf("Expected %d rows, but got: %d, %v", n/2+1, len(rows), err)
+	}
+}
@@ -0,0 +1,25 @@
+package configstore
+
+import (
+	"context"
+	"time"
+)
+
+// WithDefaultTimeout 为不接收 ctx 的 SaveConfig、ForceWrite、LoadConfig、LoadConfigOrDefault、
+// UpdateConfig 和 Stats 设置超时，超过 d 仍未完成（包括等待其他 goroutine 释放锁的时间）时返回 ErrTimeout。
+// 超时后调用方立即返回；等待锁的调用超时后放弃等待，不会在锁释放后继续读写，
+// 因此存储后端阻塞时之后的调用同样在 d 之后返回 ErrTimeout，而不是一直等待。
+// 接收 ctx 的方法只使用 ctx 的超时。d 不是正数时不设置超时，这是默认行为。
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(c *storeConfig) {
+		c.defaultTimeout = d
+	}
+}
+
+// 返回不接收 ctx 的方法使用的 ctx，超时的原因为 ErrTimeout
+func (cs *ConfigStore[T]) defaultContext() (context.Context, context.CancelFunc) {
+	if cs.defaultTimeout <= 0 {
+		return context.Background(), func() {}
+	}
+	return context.WithTimeoutCause(context.Background(), cs.defaultTimeout, ErrTimeout)
+}
@@ -0,0 +1,74 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithDefaultTimeout(t *testing.T) {
+	backend := &blockingBackend{release: make(chan struct{})}
+	cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"), WithDefaultTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	err = cs.SaveConfig(myConfig{Username: "testuser"})
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrTimeout, but got: %v", err)
+	}
+
+	// 等待锁的读取同样会超时
+	config, err := cs.LoadConfigOrDefault(myConfig{Username: "default"})
+	if !errors.Is(err, ErrTimeout) || config.Username != "default" {
+		t.Errorf("Expected default config and ErrTimeout, but got: %+v, %v", config, err)
+	}
+
+	// 后台的写入完成后释放锁
+	close(backend.release)
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+	if config, err := cs.LoadConfig(); err != nil || config.Username != "testuser" {
+		t.Errorf("Expected username testuser, but got: %+v, %v", config, err)
+	}
+}
+
+func TestDefaultTimeoutWaitingForLock(t *testing.T) {
+	backend := &blockingBackend{release: make(chan struct{})}
+	cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"), WithDefaultTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "first"}); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, but got: %v", err)
+	}
+
+	// 后端仍然阻塞时，等待锁的调用同样超时返回，而不是一直阻塞
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := cs.SaveConfig(myConfig{Username: "second"}); !errors.Is(err, ErrTimeout) {
+			t.Errorf("Expected ErrTimeout from SaveConfig, but got: %v", err)
+		}
+		if _, err := cs.Stats(); !errors.Is(err, ErrTimeout) {
+			t.Errorf("Expected ErrTimeout from Stats, but got: %v", err)
+		}
+		err := cs.UpdateConfig(func(c myConfig) (myConfig, error) { return myConfig{Username: "third"}, nil })
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("Expected ErrTimeout from UpdateConfig, but got: %v", err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected calls to time out while the backend is blocked")
+	}
+
+	// 超时放弃等待的调用不会在锁释放后继续写入
+	close(backend.release)
+	time.Sleep(50 * time.Millisecond)
+	if config, err := cs.LoadConfig(); err != nil || config.Username != "first" {
+		t.Errorf("Expected only the first write to complete, but got: %+v, %v", config, err)
+	}
+}
@@ -0,0 +1,59 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// 创建 Tracer 时使用的 instrumentation 名称
+const tracerName = "github.com/JanusHuang/configstore"
+
+// span 属性
+const (
+	attrFile      = attribute.Key("configstore.file")
+	attrFormat    = attribute.Key("configstore.format")
+	attrEncrypted = attribute.Key("configstore.encrypted")
+	attrCacheHit  = attribute.Key("configstore.cache_hit")
+)
+
+// WithTracer 使用 tp 为读取和保存创建 OpenTelemetry span，名称分别为 configstore.Load 和 configstore.Save。
+// 未设置时不创建任何 span。
+func WithTracer(tp trace.TracerProvider) Option {
+	return func(c *storeConfig) {
+		if tp == nil {
+			c.tracer = nil
+			return
+		}
+		c.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// 开始一个 span，未设置 WithTracer 时返回不记录任何内容的 span，不会修改 ctx 中调用方的 span
+func (cs *ConfigStore[T]) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	if cs.tracer == nil {
+		return ctx, noop.Span{}
+	}
+	var file string
+	if fb, ok := cs.fileBackend(); ok {
+		file = fb.filename
+	}
+	return cs.tracer.Start(ctx, name, trace.WithAttributes(
+		attrFile.String(file),
+		attrFormat.String(cs.format.String()),
+		attrEncrypted.Bool(cs.cipherMode != CipherModeNone),
+	))
+}
+
+// 记录错误并结束 span，还没有保存过配置不视为错误
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrNoConfig) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
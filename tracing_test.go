@@ -0,0 +1,85 @@
+package configstore
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// 返回 span 中名为 key 的属性
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	filename := filepath.Join(t.TempDir(), "trace.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithTracer(tp), WithCache())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cs.LoadConfigOrDefault(myConfig{}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, but got: %d", len(spans))
+	}
+	names := []string{"configstore.Save", "configstore.Load", "configstore.Load"}
+	for i, span := range spans {
+		if span.Name() != names[i] {
+			t.Errorf("Expected span %s, but got: %s", names[i], span.Name())
+		}
+		if v, _ := spanAttr(span, attrFile); v.AsString() != filename {
+			t.Errorf("Expected file attribute %s, but got: %s", filename, v.AsString())
+		}
+		if v, _ := spanAttr(span, attrFormat); v.AsString() != "JSON" {
+			t.Errorf("Expected format attribute JSON, but got: %s", v.AsString())
+		}
+		if v, _ := spanAttr(span, attrEncrypted); !v.AsBool() {
+			t.Error("Expected encrypted attribute to be true")
+		}
+	}
+	// 第一次读取没有命中缓存，第二次命中
+	for i, want := range []bool{false, true} {
+		v, ok := spanAttr(spans[i+1], attrCacheHit)
+		if !ok || v.AsBool() != want {
+			t.Errorf("Expected cache_hit %v, but got: %v", want, v.AsBool())
+		}
+	}
+}
+
+func TestTracerError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	errSave := errors.New("save failed")
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(failingBackend{err: errSave}), WithTracer(tp))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); !errors.Is(err, errSave) {
+		t.Fatalf("Expected save error, but got: %v", err)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected one span with error status, but got: %+v", spans)
+	}
+}
@@ -0,0 +1,29 @@
+package configstore
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithMaxAge 在文件头部记录保存时间，读取时如果距离保存已经超过 d，返回 ErrConfigExpired 而不是保存的配置，
+// 适用于需要定期从远程数据源刷新的配置。启用前保存的、没有记录保存时间的文件同样视为过期。
+// WithCache 缓存的配置也会在过期后失效。d 为 0 时不检查。
+func WithMaxAge(d time.Duration) Option {
+	return func(c *storeConfig) {
+		c.ttl = d
+	}
+}
+
+// 检查保存时间为 savedAt 的配置是否已经过期
+func (cs *ConfigStore[T]) checkExpired(savedAt time.Time) error {
+	if cs.ttl <= 0 {
+		return nil
+	}
+	if savedAt.IsZero() {
+		return fmt.Errorf("%w: no saved time recorded", ErrConfigExpired)
+	}
+	if age := time.Since(savedAt); age > cs.ttl {
+		return fmt.Errorf("%w: saved %v ago", ErrConfigExpired, age.Truncate(time.Second))
+	}
+	return nil
+}
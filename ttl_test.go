@@ -0,0 +1,99 @@
+package configstore
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithMaxAge(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "ttl.data")
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithMaxAge(time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config := myConfig{Username: "testuser"}
+	before := time.Now()
+	if err := cs.SaveConfig(config); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	loaded, err := cs.LoadConfigOrDefault(myConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if loaded != config {
+		t.Errorf("Expected config to be %+v, but got: %+v", config, loaded)
+	}
+
+	stats, err := cs.Stats()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if stats.SavedAt.Before(before) || stats.SavedAt.After(time.Now()) {
+		t.Errorf("Expected SavedAt to be the save time, but got: %v", stats.SavedAt)
+	}
+
+	// 有效期很短的 ConfigStore 读取同一个文件时视为过期
+	short, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithMaxAge(time.Nanosecond))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defaultConfig := myConfig{Username: "default"}
+	loaded, err = short.LoadConfigOrDefault(defaultConfig)
+	if !errors.Is(err, ErrConfigExpired) {
+		t.Errorf("Expected ErrConfigExpired, but got: %v", err)
+	}
+	if loaded != defaultConfig {
+		t.Errorf("Expected default config, but got: %+v", loaded)
+	}
+}
+
+func TestWithMaxAgeNoSavedTime(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "ttl.data")
+	cs, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	stats, err := cs.Stats()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !stats.SavedAt.IsZero() {
+		t.Errorf("Expected no SavedAt without WithMaxAge, but got: %v", stats.SavedAt)
+	}
+
+	// 没有记录保存时间的文件视为过期
+	ttl, err := New[myConfig](WithFile(filename), WithKey("0123456789abcdef"), WithMaxAge(time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := ttl.LoadConfig(); !errors.Is(err, ErrConfigExpired) {
+		t.Errorf("Expected ErrConfigExpired, but got: %v", err)
+	}
+}
+
+func TestWithMaxAgeCache(t *testing.T) {
+	cs, err := New[myConfig](WithBackend(NewMemoryBackend()), WithKey("0123456789abcdef"), WithMaxAge(50*time.Millisecond), WithCache())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.LoadConfig(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !cs.IsCached() {
+		t.Fatal("Expected config to be cached")
+	}
+
+	// 缓存的配置过期后不再返回
+	time.Sleep(100 * time.Millisecond)
+	if _, err := cs.LoadConfig(); !errors.Is(err, ErrConfigExpired) {
+		t.Errorf("Expected ErrConfigExpired, but got: %v", err)
+	}
+}
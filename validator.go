@@ -0,0 +1,46 @@
+package configstore
+
+import "fmt"
+
+// Validator 校验配置的合法性，SaveConfig 在写入前、LoadConfig 在解析后都会调用
+type Validator[T any] interface {
+	Validate(T) error
+}
+
+// FuncValidator 将普通函数转换为 Validator
+type FuncValidator[T any] func(T) error
+
+func (f FuncValidator[T]) Validate(config T) error {
+	return f(config)
+}
+
+// WithValidator 设置配置校验器。校验失败时 SaveConfig 不写入数据，
+// LoadConfigOrDefault 返回读取到的配置和错误而不是默认配置。错误可以用 errors.Is 与 ErrInvalidConfig 比较。
+func WithValidator[T any](v Validator[T]) Option {
+	return func(c *storeConfig) {
+		c.validator = v
+	}
+}
+
+// 将 WithValidator 设置的校验器转换为具体类型
+func validatorOf[T any](v any) (Validator[T], error) {
+	if v == nil {
+		return nil, nil
+	}
+	validator, ok := v.(Validator[T])
+	if !ok {
+		var zero T
+		return nil, fmt.Errorf("%w: validator %T does not match config type %T", ErrInvalidOption, v, zero)
+	}
+	return validator, nil
+}
+
+// 使用校验器和 JSON Schema 检查配置，都没有设置时直接返回 nil
+func (cs *ConfigStore[T]) validate(config T) error {
+	if cs.validator != nil {
+		if err := cs.validator.Validate(config); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+	return cs.validateSchema(config)
+}
@@ -0,0 +1,73 @@
+package configstore
+
+import (
+	"errors"
+	"testing"
+)
+
+var errEmptyUsername = errors.New("username must not be empty")
+
+func requireUsername(c myConfig) error {
+	if c.Username == "" {
+		return errEmptyUsername
+	}
+	return nil
+}
+
+func TestValidatorSave(t *testing.T) {
+	backend := NewMemoryBackend()
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef",
+		WithBackend(backend), WithValidator(FuncValidator[myConfig](requireUsername)))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 校验失败时不写入数据
+	err = cs.SaveConfig(myConfig{Password: "testpassword"})
+	if !errors.Is(err, ErrInvalidConfig) || !errors.Is(err, errEmptyUsername) {
+		t.Errorf("Expected validation error, but got: %v", err)
+	}
+	if data, _ := backend.Read(); len(data) != 0 {
+		t.Errorf("Expected nothing to be written, but got %d bytes", len(data))
+	}
+
+	if err := cs.SaveConfig(myConfig{Username: "testuser"}); err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+}
+
+func TestValidatorLoad(t *testing.T) {
+	backend := NewMemoryBackend()
+	// 先用没有校验器的 ConfigStore 写入不合法的配置
+	plain, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(backend))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	stored := myConfig{Password: "testpassword"}
+	if err := plain.SaveConfig(stored); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef",
+		WithBackend(backend), WithValidator(FuncValidator[myConfig](requireUsername)))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	config, err := cs.LoadConfigOrDefault(myConfig{Username: "default"})
+	if !errors.Is(err, errEmptyUsername) {
+		t.Errorf("Expected validation error, but got: %v", err)
+	}
+	// 不使用默认配置替换
+	if config != stored {
+		t.Errorf("Expected stored config %+v, but got: %+v", stored, config)
+	}
+}
+
+func TestValidatorTypeMismatch(t *testing.T) {
+	_, err := NewConfigStore[myConfig]("", "0123456789abcdef",
+		WithBackend(NewMemoryBackend()),
+		WithValidator(FuncValidator[string](func(string) error { return nil })))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Error("Expected an error for a validator of the wrong type, but got nil")
+	}
+}
@@ -0,0 +1,153 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce 是 Watch 合并连续写入事件的默认间隔
+const DefaultWatchDebounce = 100 * time.Millisecond
+
+// Watch 监听配置文件的变化（WRITE 和 CREATE 事件），变化后重新读取配置并通过 onChange 回调。
+// 监听的是文件所在的目录，因此基于重命名的原子写入同样可以被检测到；短时间内的连续事件会被合并为一次回调。
+// 其他存储后端需要实现 BackendWatcher 接口，由后端负责发现变化。
+// 返回的 cancel 函数会停止监听并关闭底层的 fsnotify watcher，不能在 onChange 中同步调用。
+func (cs *ConfigStore[T]) Watch(ctx context.Context, onChange func(T, error)) (cancel func(), err error) {
+	defer func() { err = cs.wrapError("watch", err) }()
+	cs.mu.RLock()
+	closed := cs.closed
+	cs.mu.RUnlock()
+	if closed {
+		return nil, ErrStoreClosed
+	}
+	fb, ok := cs.fileBackend()
+	if !ok {
+		if w, ok := cs.backend.(BackendWatcher); ok {
+			return cs.watchBackend(ctx, w, onChange), nil
+		}
+		return nil, fmt.Errorf("%w: watch requires FileBackend or a Backend implementing BackendWatcher", ErrUnsupported)
+	}
+	target := filepath.Clean(fb.filename)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	debounce := cs.watchDebounce
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer watcher.Close()
+
+		var timer *time.Timer
+		var fire <-chan time.Time
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != target || !event.Has(fsnotify.Write|fsnotify.Create) {
+					continue
+				}
+				// 重新计时，合并连续的写入
+				if timer == nil {
+					timer = time.NewTimer(debounce)
+				} else {
+					timer.Reset(debounce)
+				}
+				fire = timer.C
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				err = cs.wrapError("watch", err)
+				cs.logger.Error("configstore: watch error", "error", err)
+				var zero T
+				onChange(zero, err)
+			case <-fire:
+				fire = nil
+				onChange(cs.reloadAndLog())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stop()
+			<-done
+		})
+	}, nil
+}
+
+// 通过 BackendWatcher 接口监听存储后端的变化
+func (cs *ConfigStore[T]) watchBackend(ctx context.Context, w BackendWatcher, onChange func(T, error)) func() {
+	ctx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := w.Watch(ctx, func() {
+			onChange(cs.reloadAndLog())
+		})
+		if err != nil && ctx.Err() == nil {
+			err = cs.wrapError("watch", err)
+			cs.logger.Error("configstore: watch error", "error", err)
+			var zero T
+			onChange(zero, err)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stop()
+			<-done
+		})
+	}
+}
+
+// 重新读取配置并记录结果，返回传给 onChange 的参数
+func (cs *ConfigStore[T]) reloadAndLog() (T, error) {
+	var zero T
+	config, err := cs.reload()
+	if err != nil && !errors.Is(err, ErrNoConfig) {
+		cs.logger.Error("configstore: reload failed", "error", err)
+	} else {
+		cs.logger.Info("configstore: config reloaded")
+	}
+	return orDefault(config, err, zero)
+}
+
+// 文件变化后重新读取配置并更新缓存，使用写锁，避免与同时进行的 SaveConfig、RotateKey 交错
+func (cs *ConfigStore[T]) reload() (T, error) {
+	cs.mu.Lock()
+	config, err := cs.loadAndCache(context.Background())
+	cs.mu.Unlock()
+	config, err = cs.afterLoad(config, err)
+	return config, cs.wrapError("watch", err)
+}
@@ -0,0 +1,103 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "watch.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key, WithWatchDebounce(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var mu sync.Mutex
+	var calls int
+	changes := make(chan myConfig, 10)
+	cancel, err := cs.Watch(context.Background(), func(config myConfig, err error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		if err != nil {
+			t.Errorf("Expected no error, but got: %v", err)
+			return
+		}
+		changes <- config
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defer cancel()
+
+	// 另一个进程（这里用另一个实例模拟）连续写入多次
+	writer, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2", "v3"} {
+		if err := writer.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	select {
+	case config := <-changes:
+		if config.Username != "v3" {
+			t.Errorf("Expected username to be v3, but got: %s", config.Username)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for change notification")
+	}
+
+	// 连续写入被合并为一次回调
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	if calls != 1 {
+		t.Errorf("Expected 1 callback, but got: %d", calls)
+	}
+	mu.Unlock()
+}
+
+func TestWatchCancel(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "watch.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key, WithWatchDebounce(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	changes := make(chan myConfig, 10)
+	cancel, err := cs.Watch(context.Background(), func(config myConfig, err error) {
+		changes <- config
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	cancel()
+	// 重复调用 cancel 是安全的
+	cancel()
+
+	if err := cs.SaveConfig(myConfig{Username: "after-cancel"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	select {
+	case config := <-changes:
+		t.Errorf("Expected no callback after cancel, but got: %+v", config)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchRequiresFileBackend(t *testing.T) {
+	cs, err := NewConfigStore[myConfig]("", "0123456789abcdef", WithBackend(NewMemoryBackend()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := cs.Watch(context.Background(), func(myConfig, error) {}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected an error for a non-file backend, but got nil")
+	}
+}
@@ -0,0 +1,259 @@
+package configstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultPollInterval 是 Watcher 轮询不支持推送通知的存储后端的默认间隔
+const DefaultPollInterval = 5 * time.Second
+
+// WatchOption 用于配置 Watcher
+type WatchOption func(*watcherConfig)
+
+type watcherConfig struct {
+	pollInterval time.Duration
+}
+
+// WithPollInterval 设置轮询存储后端的间隔，默认为 DefaultPollInterval。
+// 只对既不是 FileBackend、也没有实现 BackendWatcher 的存储后端生效。
+func WithPollInterval(d time.Duration) WatchOption {
+	return func(c *watcherConfig) {
+		c.pollInterval = d
+	}
+}
+
+// Watcher 通过 channel 发送配置的变化，便于在 select 中与其他事件一起处理。
+// FileBackend 和实现了 BackendWatcher 的存储后端使用 Watch 监听，其他存储后端定期轮询。
+type Watcher[T any] struct {
+	store        *ConfigStore[T]
+	pollInterval time.Duration
+	// NewPoller 创建的 Watcher 总是轮询，FileBackend 比较文件的修改时间
+	statPoll bool
+	c        chan T
+	errs     chan error
+
+	mu      sync.Mutex
+	started bool
+	cancel  func()
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewWatcher 创建一个监听 store 的 Watcher，调用 Start 后开始监听
+func NewWatcher[T any](store *ConfigStore[T], opts ...WatchOption) *Watcher[T] {
+	cfg := watcherConfig{pollInterval: DefaultPollInterval}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.pollInterval <= 0 {
+		cfg.pollInterval = DefaultPollInterval
+	}
+	return &Watcher[T]{
+		store:        store,
+		pollInterval: cfg.pollInterval,
+		c:            make(chan T),
+		errs:         make(chan error),
+		stop:         make(chan struct{}),
+	}
+}
+
+// NewPoller 创建一个通过轮询监听 store 的 Watcher，用法与 NewWatcher 相同，调用 Start 后开始监听。
+// 适用于 inotify/kqueue 无法工作的环境，例如 macOS 上 Docker 的 bind mount 和 WSL1。
+// FileBackend 每隔 interval 检查一次文件的修改时间和大小（os.Stat），与上一次成功读取时不同就重新读取配置，
+// 读取失败时在之后的每次轮询中重试；
+// 其他存储后端与 NewWatcher 的轮询相同，比较存储后端中的原始数据。interval 不是正数时使用 DefaultPollInterval。
+func NewPoller[T any](store *ConfigStore[T], interval time.Duration) *Watcher[T] {
+	w := NewWatcher(store, WithPollInterval(interval))
+	w.statPoll = true
+	return w
+}
+
+// C 返回接收变化后配置的 channel，Stop 后关闭
+func (w *Watcher[T]) C() <-chan T {
+	return w.c
+}
+
+// Err 返回接收监听和读取错误的 channel，Stop 后关闭
+func (w *Watcher[T]) Err() <-chan error {
+	return w.errs
+}
+
+// Start 开始监听，ctx 被取消后停止监听，但 channel 直到 Stop 才会关闭。每个 Watcher 只能启动一次。
+func (w *Watcher[T]) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	select {
+	case <-w.stop:
+		return fmt.Errorf("%w: watcher stopped", ErrInvalidOption)
+	default:
+	}
+	if w.started {
+		return fmt.Errorf("%w: watcher already started", ErrInvalidOption)
+	}
+
+	var cancel func()
+	var err error
+	if w.statPoll {
+		cancel, err = w.poll(ctx)
+	} else if cancel, err = w.store.Watch(ctx, w.send); errors.Is(err, ErrUnsupported) {
+		cancel, err = w.poll(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	w.started, w.cancel = true, cancel
+	return nil
+}
+
+// Stop 停止监听并关闭 C 和 Err 返回的 channel，可以多次调用
+func (w *Watcher[T]) Stop() {
+	w.once.Do(func() {
+		// 先让阻塞在发送上的回调返回，再等待监听结束，之后不会再有发送
+		close(w.stop)
+		w.mu.Lock()
+		cancel := w.cancel
+		w.mu.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+		close(w.c)
+		close(w.errs)
+	})
+}
+
+// 将变化发送到对应的 channel，Stop 后直接丢弃
+func (w *Watcher[T]) send(config T, err error) {
+	if err != nil {
+		select {
+		case w.errs <- err:
+		case <-w.stop:
+		}
+		return
+	}
+	select {
+	case w.c <- config:
+	case <-w.stop:
+	}
+}
+
+// 定期检查存储后端，发生变化时重新读取配置
+func (w *Watcher[T]) poll(ctx context.Context) (func(), error) {
+	cs := w.store
+	changed, commit, err := w.changeDetector()
+	if err != nil {
+		return nil, cs.wrapError("watch", err)
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			ok, err := changed()
+			if err != nil {
+				err = cs.wrapError("watch", err)
+				cs.logger.Error("configstore: watch error", "error", err)
+				var zero T
+				w.send(zero, err)
+				continue
+			}
+			if ok {
+				// 只有读取成功后才记录当前状态，读取失败时下一次轮询会重试并再次报告
+				config, err := cs.reloadAndLog()
+				if err == nil {
+					commit()
+				}
+				w.send(config, err)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stop()
+			<-done
+		})
+	}, nil
+}
+
+// 返回检查存储后端是否发生变化的函数：NewPoller 监听 FileBackend 时比较文件的修改时间和大小，否则比较原始数据。
+// changed 与上一次成功读取时记录的状态比较，commit 将最近一次 changed 看到的状态记录为成功读取的状态。
+func (w *Watcher[T]) changeDetector() (changed func() (bool, error), commit func(), err error) {
+	cs := w.store
+	if fb, ok := cs.fileBackend(); ok && w.statPoll {
+		last, err := statFile(fb.filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		current := last
+		changed = func() (bool, error) {
+			state, err := statFile(fb.filename)
+			if err != nil {
+				return false, err
+			}
+			current = state
+			return !current.equal(last), nil
+		}
+		return changed, func() { last = current }, nil
+	}
+
+	last, err := cs.readRaw()
+	if err != nil {
+		return nil, nil, err
+	}
+	current := last
+	changed = func() (bool, error) {
+		data, err := cs.readRaw()
+		if err != nil {
+			return false, err
+		}
+		current = data
+		return !bytes.Equal(current, last), nil
+	}
+	return changed, func() { last = current }, nil
+}
+
+// fileState 是轮询时比较的文件状态
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (s fileState) equal(other fileState) bool {
+	return s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+// 返回文件的修改时间和大小，文件不存在时返回零值
+func statFile(filename string) (fileState, error) {
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		return fileState{}, nil
+	}
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// 读取存储后端中未解密的数据
+func (cs *ConfigStore[T]) readRaw() ([]byte, error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	if cs.closed {
+		return nil, ErrStoreClosed
+	}
+	return cs.backend.Read()
+}
@@ -0,0 +1,202 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "watcher.data")
+	key := "0123456789abcdef"
+	cs, err := NewConfigStore[myConfig](filename, key, WithWatchDebounce(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	w := NewWatcher(cs)
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defer w.Stop()
+
+	writer, err := NewConfigStore[myConfig](filename, key)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := writer.SaveConfig(myConfig{Username: "v1"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	select {
+	case config := <-w.C():
+		if config.Username != "v1" {
+			t.Errorf("Expected username to be v1, but got: %s", config.Username)
+		}
+	case err := <-w.Err():
+		t.Fatalf("Expected no error, but got: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for change notification")
+	}
+
+	if err := w.Start(context.Background()); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption when starting twice, but got: %v", err)
+	}
+}
+
+func TestWatcherPoll(t *testing.T) {
+	backend := NewMemoryBackend()
+	cs, err := New[myConfig](WithBackend(backend), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	w := NewWatcher(cs, WithPollInterval(10*time.Millisecond))
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := cs.SaveConfig(myConfig{Username: "polled"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	select {
+	case config := <-w.C():
+		if config.Username != "polled" {
+			t.Errorf("Expected username to be polled, but got: %s", config.Username)
+		}
+	case err := <-w.Err():
+		t.Fatalf("Expected no error, but got: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for change notification")
+	}
+
+	// Stop 关闭两个 channel，可以重复调用
+	if err := cs.SaveConfig(myConfig{Username: "unread"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	w.Stop()
+	w.Stop()
+	if _, ok := <-w.C(); ok {
+		t.Errorf("Expected C to be closed after Stop")
+	}
+	if _, ok := <-w.Err(); ok {
+		t.Errorf("Expected Err to be closed after Stop")
+	}
+}
+
+func TestNewPoller(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "poller.data")
+	key := "0123456789abcdef"
+	cs, err := New[myConfig](WithFile(filename), WithKey(key))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 将修改时间设置到过去，保证写入后修改时间不同
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filename, past, past); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	w := NewPoller(cs, 10*time.Millisecond)
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defer w.Stop()
+
+	writer, err := New[myConfig](WithFile(filename), WithKey(key))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := writer.SaveConfig(myConfig{Username: "polled"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	select {
+	case config := <-w.C():
+		if config.Username != "polled" {
+			t.Errorf("Expected username to be polled, but got: %s", config.Username)
+		}
+	case err := <-w.Err():
+		t.Fatalf("Expected no error, but got: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for change notification")
+	}
+
+	// 修改时间不变时不重新读取
+	select {
+	case config := <-w.C():
+		t.Errorf("Expected no notification, but got: %+v", config)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNewPollerRetriesFailedReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "poller.data")
+	key := "0123456789abcdef"
+	cs, err := New[myConfig](WithFile(filename), WithKey(key))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	w := NewPoller(cs, 10*time.Millisecond)
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defer w.Stop()
+
+	// 使用其他 key 写入，读取失败
+	other, err := New[myConfig](WithFile(filename), WithKey("fedcba9876543210"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := other.SaveConfig(myConfig{Username: "other"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 文件没有再次变化，但读取失败后每次轮询都会重试并报告
+	for i := 0; i < 2; i++ {
+		select {
+		case config := <-w.C():
+			t.Fatalf("Expected a reload error, but got: %+v", config)
+		case err := <-w.Err():
+			if !errors.Is(err, ErrDecryptionFailed) {
+				t.Errorf("Expected ErrDecryptionFailed, but got: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for reload error")
+		}
+	}
+
+	writer, err := New[myConfig](WithFile(filename), WithKey(key))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := writer.ForceWrite(myConfig{Username: "polled"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filename, future, future); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for {
+		select {
+		case config := <-w.C():
+			if config.Username != "polled" {
+				t.Errorf("Expected username to be polled, but got: %s", config.Username)
+			}
+		case <-w.Err():
+			// 写入前已经开始的轮询仍可能报告旧文件的错误
+			continue
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for change notification")
+		}
+		break
+	}
+
+	// 成功读取后不再重新读取
+	select {
+	case config := <-w.C():
+		t.Errorf("Expected no notification, but got: %+v", config)
+	case err := <-w.Err():
+		t.Errorf("Expected no error, but got: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
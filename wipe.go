@@ -0,0 +1,86 @@
+package configstore
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultSecureDeletePasses 是 SecureDelete 默认覆盖文件的次数
+const DefaultSecureDeletePasses = 3
+
+// WithSecureDeletePasses 设置 SecureDelete 使用随机数据覆盖文件的次数，默认为 DefaultSecureDeletePasses
+func WithSecureDeletePasses(n int) Option {
+	return func(c *storeConfig) {
+		c.wipePasses = n
+	}
+}
+
+// SecureDelete 与 DeleteConfig 相同，但在删除配置文件、备份和归档文件之前先使用随机数据多次覆盖文件内容，
+// 仅对 FileBackend 生效。
+//
+// 警告：带日志的文件系统、写时复制的文件系统（如 btrfs、ZFS、APFS）以及带磨损均衡的 SSD 可能将新数据写到
+// 不同的物理位置，旧数据仍可能残留在磁盘上，因此覆盖不能在密码学意义上保证数据无法恢复，只能降低恢复的可能性。
+// 需要可靠地销毁数据时应轮换或销毁加密 key。
+func (cs *ConfigStore[T]) SecureDelete() (err error) {
+	defer func() { err = cs.wrapError("delete", err) }()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.closed {
+		return ErrStoreClosed
+	}
+	fb, ok := cs.fileBackend()
+	if !ok {
+		return fmt.Errorf("%w: secure delete is only supported by FileBackend", ErrUnsupported)
+	}
+	if cs.wipePasses < 1 {
+		return fmt.Errorf("%w: secure delete passes must be at least 1, got %d", ErrInvalidOption, cs.wipePasses)
+	}
+	unlock, err := cs.lockFile(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cs.invalidateCache()
+	names, err := backupFiles(fb.filename)
+	if err != nil {
+		return err
+	}
+	names = append(names, archiveName(fb.filename), checksumName(fb.filename), fb.filename+".tmp", fb.filename)
+	for _, name := range names {
+		if err := wipeFile(name, cs.wipePasses, cs.random); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 使用 random 中的数据覆盖 name 的全部内容 passes 次并刷新到磁盘，然后删除文件。文件不存在时返回 nil。
+func wipeFile(name string, passes int, random io.Reader) error {
+	f, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	for i := 0; err == nil && i < passes; i++ {
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			break
+		}
+		if _, err = io.CopyN(f, random, info.Size()); err != nil {
+			break
+		}
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
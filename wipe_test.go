@@ -0,0 +1,97 @@
+package configstore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 统计读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestWipeFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "secret.data")
+	content := bytes.Repeat([]byte("secret"), 100)
+	if err := os.WriteFile(name, content, 0600); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// 通过硬链接检查同一个文件的内容被覆盖
+	link := filepath.Join(dir, "link.data")
+	if err := os.Link(name, link); err != nil {
+		t.Skipf("hard links are not supported: %v", err)
+	}
+
+	random := &countingReader{r: rand.Reader}
+	if err := wipeFile(name, 3, random); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if random.n != int64(3*len(content)) {
+		t.Errorf("Expected %d random bytes, but got: %d", 3*len(content), random.n)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("Expected file to be removed, but got: %v", err)
+	}
+	data, _ := os.ReadFile(link)
+	if len(data) != len(content) || bytes.Contains(data, []byte("secret")) {
+		t.Errorf("Expected file content to be overwritten")
+	}
+
+	// 文件不存在时返回 nil
+	if err := wipeFile(name, 3, rand.Reader); err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+}
+
+func TestSecureDelete(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "secure.data")
+	cs, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithBackup(2), WithSecureDeletePasses(1))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{"v1", "v2"} {
+		if err := cs.SaveConfig(myConfig{Username: name}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	if err := cs.SecureDelete(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, name := range []string{filename, backupName(filename, 1)} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, but got: %v", name, err)
+		}
+	}
+	if config, err := cs.LoadConfigOrDefault(myConfig{Username: "default"}); err != nil || config.Username != "default" {
+		t.Errorf("Expected default config after delete, but got: %+v, %v", config, err)
+	}
+
+	invalid, err := NewConfigStore[myConfig](filename, "0123456789abcdef", WithSecureDeletePasses(0))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := invalid.SecureDelete(); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption, but got: %v", err)
+	}
+
+	memory, err := New[myConfig](WithBackend(NewMemoryBackend()), WithKey("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := memory.SecureDelete(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, but got: %v", err)
+	}
+}